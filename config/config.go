@@ -14,6 +14,17 @@ type Config struct {
 	Port      string
 	ExcelFile string
 	APIURL    string
+	
+	// Webhooks: suscripciones y outbox persistidos en WebhooksFile, y los eventos aún sin pasar al
+	// outbox en WebhooksFile + ".journal"; los secretos se cifran con WebhookSecretKey o, si está
+	// vacía, con una llave generada en WebhooksFile + ".key"
+	WebhooksFile       string
+	WebhookMaxAttempts int
+	WebhookSecretKey   string
+	
+	// Reglas de validación (también definen la plantilla de importación)
+	TelefonoDigitos int
+	CorreoDominios  []string
 }
 
 // OptimizedConfig configuración extendida para optimizaciones
@@ -47,6 +58,13 @@ func Load() *Config {
 		Port:      getEnv("PORT", "8080"),
 		ExcelFile: getEnv("EXCEL_FILE", "contactos.xlsx"),
 		APIURL:    getEnv("API_URL", "http://localhost:8080"),
+		
+		WebhooksFile:       getEnv("WEBHOOKS_FILE", "webhooks.json"),
+		WebhookMaxAttempts: getEnvInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		WebhookSecretKey:   getEnv("WEBHOOK_SECRET_KEY", ""),
+		
+		TelefonoDigitos: getEnvInt("TELEFONO_DIGITOS", 10),
+		CorreoDominios:  getEnvList("CORREO_DOMINIOS", nil),
 	}
 }
 
@@ -185,6 +203,21 @@ func getEnvInt64(key string, defaultValue int64) int64 {
 	return defaultValue
 }
 
+// getEnvList lee una lista separada por comas
+func getEnvList(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func getEnvBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
 		if boolValue, err := strconv.ParseBool(value); err == nil {
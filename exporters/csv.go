@@ -0,0 +1,45 @@
+// exporters/csv.go
+package exporters
+
+import (
+	"encoding/csv"
+	"io"
+
+	"contactos-api/models"
+)
+
+// utf8BOM permite que Excel abra el CSV con acentos correctos
+const utf8BOM = "\ufeff"
+
+type csvWriter struct {
+	csv     *csv.Writer
+	columns []string
+	record  []string
+}
+
+func newCSVWriter(w io.Writer, columns []string) (*csvWriter, error) {
+	if _, err := io.WriteString(w, utf8BOM); err != nil {
+		return nil, err
+	}
+
+	cw := &csvWriter{csv: csv.NewWriter(w), columns: columns, record: make([]string, len(columns))}
+	for i, column := range columns {
+		cw.record[i] = columnHeaders[column]
+	}
+	if err := cw.csv.Write(cw.record); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+func (cw *csvWriter) Write(contacto models.Contacto) error {
+	for i, column := range cw.columns {
+		cw.record[i] = columnValue(contacto, column)
+	}
+	return cw.csv.Write(cw.record)
+}
+
+func (cw *csvWriter) Close() error {
+	cw.csv.Flush()
+	return cw.csv.Error()
+}
@@ -0,0 +1,191 @@
+// exporters/error_workbook.go
+package exporters
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/plandem/xlsx"
+	"github.com/plandem/xlsx/format/styles"
+	"github.com/plandem/xlsx/types"
+	"github.com/plandem/xlsx/types/hyperlink"
+	colOptions "github.com/plandem/xlsx/types/options/column"
+)
+
+const (
+	errorWorkbookDataSheet   = "Contactos"
+	errorWorkbookErrorsSheet = "Errores"
+	// maxErrorHyperlinks límite de Excel de hipervínculos por hoja (con margen)
+	maxErrorHyperlinks = 65000
+)
+
+// WorkbookRow fila del libro anotado con los valores tal como se cargaron
+type WorkbookRow struct {
+	SourceRow int       // Fila en el archivo cargado (0 si el contacto se agregó después)
+	Values    [4]string // ClaveCliente, Nombre, Correo, TelefonoContacto
+	Numeric   bool      // La clave es numérica válida (se escribe como número)
+}
+
+// WorkbookError error a marcar en una celda del libro anotado
+type WorkbookError struct {
+	SourceRow  int
+	Column     string // Letra A-D o "general" (se marcan las celdas vacías de la fila)
+	Field      string
+	Value      string
+	Message    string
+	Suggestion string
+}
+
+// WriteErrorWorkbook genera una copia del libro con las celdas inválidas resaltadas y comentadas,
+// más una hoja "Errores" con un hipervínculo a cada celda. La hoja de datos va primero para que
+// el archivo corregido se pueda volver a importar tal cual.
+func WriteErrorWorkbook(w io.Writer, rows []WorkbookRow, rowErrors []WorkbookError) error {
+	xl := xlsx.New()
+	defer xl.Close()
+
+	headerStyle := xl.AddStyles(styles.New(styles.Font.Bold))
+	errorStyle := xl.AddStyles(styles.New(
+		styles.Font.Color("#9C0006"),
+		styles.Fill.Background("#FFC7CE"),
+		styles.Fill.Type(styles.PatternTypeSolid),
+	))
+	textColumn := xl.AddStyles(styles.New(styles.NumberFormat("@")))
+
+	// 📄 Hoja de datos
+	data := xl.AddSheet(errorWorkbookDataSheet)
+	data.Col(0).SetOptions(colOptions.New(colOptions.Width(14)))
+	data.Col(1).SetOptions(colOptions.New(colOptions.Width(32)))
+	data.Col(2).SetOptions(colOptions.New(colOptions.Width(32)))
+	data.Col(3).SetOptions(colOptions.New(colOptions.Width(18), colOptions.Styles(textColumn)))
+
+	for i, header := range headersFor(Columns) {
+		cell := data.Cell(i, 0)
+		cell.SetText(header)
+		cell.SetStyles(headerStyle)
+	}
+
+	generatedRow := make(map[int]int, len(rowErrors)) // fila original -> fila del libro (base 0)
+	for i, row := range rows {
+		rowIndex := i + 1
+		if row.SourceRow > 0 {
+			generatedRow[row.SourceRow] = rowIndex
+		}
+		for col, value := range row.Values {
+			cell := data.Cell(col, rowIndex)
+			if col == 0 && row.Numeric {
+				if n, err := strconv.Atoi(value); err == nil {
+					cell.SetInt(n)
+					continue
+				}
+			}
+			cell.SetText(value)
+		}
+	}
+
+	// Agrupar errores por celda: una celda puede acumular varios mensajes
+	type cellKey struct{ col, row int }
+	comments := make(map[cellKey][]string)
+	targets := make([]cellKey, len(rowErrors))
+	for i, rowError := range rowErrors {
+		rowIndex, ok := generatedRow[rowError.SourceRow]
+		if !ok {
+			targets[i] = cellKey{-1, -1}
+			continue
+		}
+		for _, col := range errorColumns(rowError, rows[rowIndex-1]) {
+			key := cellKey{col, rowIndex}
+			comments[key] = append(comments[key], fmt.Sprintf("%s\nSugerencia: %s", rowError.Message, rowError.Suggestion))
+			if targets[i].row == 0 {
+				targets[i] = key
+			}
+		}
+	}
+
+	// Orden estable para que el archivo sea reproducible
+	keys := make([]cellKey, 0, len(comments))
+	for key := range comments {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].row != keys[j].row {
+			return keys[i].row < keys[j].row
+		}
+		return keys[i].col < keys[j].col
+	})
+	for _, key := range keys {
+		cell := data.Cell(key.col, key.row)
+		cell.SetStyles(errorStyle)
+		if err := cell.SetComment(strings.Join(comments[key], "\n\n")); err != nil {
+			return fmt.Errorf("error agregando comentario en %s: %w", cellRef(key.col, key.row), err)
+		}
+	}
+
+	// 📋 Hoja de errores
+	errorsSheet := xl.AddSheet(errorWorkbookErrorsSheet)
+	errorHeaders := []string{"Celda", "Fila", "Columna", "Campo", "Valor", "Error", "Sugerencia"}
+	widths := []float32{10, 8, 10, 18, 28, 45, 55}
+	for i, header := range errorHeaders {
+		errorsSheet.Col(i).SetOptions(colOptions.New(colOptions.Width(widths[i])))
+		cell := errorsSheet.Cell(i, 0)
+		cell.SetText(header)
+		cell.SetStyles(headerStyle)
+	}
+
+	for i, rowError := range rowErrors {
+		rowIndex := i + 1
+		target := targets[i]
+
+		link := errorsSheet.Cell(0, rowIndex)
+		if target.row > 0 {
+			ref := cellRef(target.col, target.row)
+			if i < maxErrorHyperlinks {
+				info := hyperlink.New(
+					hyperlink.ToRef(types.Ref(ref), errorWorkbookDataSheet),
+					hyperlink.Display(ref),
+					hyperlink.Tooltip("Ir a la celda con error"),
+				)
+				if err := link.SetValueWithHyperlink(ref, info); err != nil {
+					return fmt.Errorf("error agregando hipervínculo a %s: %w", ref, err)
+				}
+			} else {
+				link.SetText(ref)
+			}
+		}
+
+		errorsSheet.Cell(1, rowIndex).SetInt(rowError.SourceRow)
+		errorsSheet.Cell(2, rowIndex).SetText(rowError.Column)
+		errorsSheet.Cell(3, rowIndex).SetText(rowError.Field)
+		errorsSheet.Cell(4, rowIndex).SetText(rowError.Value)
+		errorsSheet.Cell(5, rowIndex).SetText(rowError.Message)
+		errorsSheet.Cell(6, rowIndex).SetText(rowError.Suggestion)
+	}
+
+	return xl.SaveAs(w)
+}
+
+// errorColumns columnas (base 0) a marcar para un error
+func errorColumns(rowError WorkbookError, row WorkbookRow) []int {
+	if len(rowError.Column) == 1 && rowError.Column[0] >= 'A' && rowError.Column[0] <= 'D' {
+		return []int{int(rowError.Column[0] - 'A')}
+	}
+
+	// Error general: marcar las celdas vacías, o la primera si no hay ninguna
+	var cols []int
+	for col, value := range row.Values {
+		if strings.TrimSpace(value) == "" {
+			cols = append(cols, col)
+		}
+	}
+	if len(cols) == 0 {
+		cols = []int{0}
+	}
+	return cols
+}
+
+// cellRef referencia A1 para índices base 0
+func cellRef(col, row int) string {
+	return columnLetter(col) + strconv.Itoa(row+1)
+}
@@ -0,0 +1,131 @@
+// exporters/exporter.go
+package exporters
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"contactos-api/models"
+)
+
+// Formatos de exportación
+const (
+	FormatCSV    = "csv"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	FormatXLSX   = "xlsx"
+	FormatVCF    = "vcf"
+)
+
+// Columns columnas exportables en su orden por defecto
+var Columns = []string{"claveCliente", "nombre", "correo", "telefonoContacto"}
+
+// columnHeaders encabezados de CSV y Excel (los mismos del archivo de origen)
+var columnHeaders = map[string]string{
+	"claveCliente":     "ClaveCliente",
+	"nombre":           "Nombre",
+	"correo":           "Correo",
+	"telefonoContacto": "TelefonoContacto",
+}
+
+// headersFor encabezados de las columnas indicadas, en el mismo orden
+func headersFor(columns []string) []string {
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		headers[i] = columnHeaders[column]
+	}
+	return headers
+}
+
+// Writer escribe contactos uno a uno en un formato de exportación
+type Writer interface {
+	// Write agrega un contacto a la salida
+	Write(contacto models.Contacto) error
+	// Close completa el documento (cierra arreglos, archivos zip, etc.)
+	Close() error
+}
+
+// New crea el writer del formato indicado con las columnas en el orden pedido
+func New(format string, w io.Writer, columns []string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, columns)
+	case FormatJSON:
+		return newJSONWriter(w, columns, false)
+	case FormatNDJSON:
+		return newJSONWriter(w, columns, true)
+	case FormatXLSX:
+		return newXLSXWriter(w, columns)
+	case FormatVCF:
+		// vCard tiene campos fijos: las columnas no aplican
+		return NewVCardWriter(w, VCardVersion4)
+	default:
+		return nil, fmt.Errorf("formato '%s' no soportado. Use csv, json, ndjson, xlsx o vcf", format)
+	}
+}
+
+// Supported indica si el formato se puede exportar
+func Supported(format string) bool {
+	switch format {
+	case FormatCSV, FormatJSON, FormatNDJSON, FormatXLSX, FormatVCF:
+		return true
+	}
+	return false
+}
+
+// ContentType tipo MIME de cada formato
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatJSON:
+		return "application/json"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case FormatVCF:
+		return "text/vcard; charset=utf-8"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// ParseColumns valida una lista separada por comas; vacía = todas las columnas
+func ParseColumns(spec string) ([]string, error) {
+	if strings.TrimSpace(spec) == "" {
+		return Columns, nil
+	}
+
+	var columns []string
+	seen := make(map[string]bool)
+	for _, column := range strings.Split(spec, ",") {
+		column = strings.TrimSpace(column)
+		if _, ok := columnHeaders[column]; !ok {
+			return nil, fmt.Errorf("columna '%s' desconocida. Use %s", column, strings.Join(Columns, ", "))
+		}
+		if seen[column] {
+			return nil, fmt.Errorf("columna '%s' repetida", column)
+		}
+		seen[column] = true
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// columnValue valor de texto de una columna
+func columnValue(contacto models.Contacto, column string) string {
+	switch column {
+	case "claveCliente":
+		return strconv.Itoa(contacto.ClaveCliente)
+	case "nombre":
+		return contacto.Nombre
+	case "correo":
+		return contacto.Correo
+	case "telefonoContacto":
+		return contacto.TelefonoContacto
+	}
+	return ""
+}
@@ -0,0 +1,72 @@
+// exporters/json.go
+package exporters
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"contactos-api/models"
+)
+
+// jsonWriter escribe un arreglo JSON o NDJSON (un objeto por línea) respetando el orden de columnas
+type jsonWriter struct {
+	w       *bufio.Writer
+	columns []string
+	ndjson  bool
+	count   int
+}
+
+func newJSONWriter(w io.Writer, columns []string, ndjson bool) (*jsonWriter, error) {
+	jw := &jsonWriter{w: bufio.NewWriter(w), columns: columns, ndjson: ndjson}
+	if !ndjson {
+		if _, err := jw.w.WriteString("["); err != nil {
+			return nil, err
+		}
+	}
+	return jw, nil
+}
+
+func (jw *jsonWriter) Write(contacto models.Contacto) error {
+	if !jw.ndjson && jw.count > 0 {
+		jw.w.WriteByte(',')
+	}
+	jw.count++
+
+	jw.w.WriteByte('{')
+	for i, column := range jw.columns {
+		if i > 0 {
+			jw.w.WriteByte(',')
+		}
+		jw.w.WriteString(strconv.Quote(column))
+		jw.w.WriteByte(':')
+		if column == "claveCliente" {
+			jw.w.WriteString(strconv.Itoa(contacto.ClaveCliente))
+			continue
+		}
+		value, err := json.Marshal(columnValue(contacto, column))
+		if err != nil {
+			return err
+		}
+		jw.w.Write(value)
+	}
+	jw.w.WriteByte('}')
+
+	if jw.ndjson {
+		jw.w.WriteByte('\n')
+	}
+
+	// Volcar periódicamente para no acumular la salida
+	if jw.w.Buffered() > 32<<10 {
+		return jw.w.Flush()
+	}
+	return nil
+}
+
+func (jw *jsonWriter) Close() error {
+	if !jw.ndjson {
+		jw.w.WriteString("]\n")
+	}
+	return jw.w.Flush()
+}
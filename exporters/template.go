@@ -0,0 +1,175 @@
+// exporters/template.go
+package exporters
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"contactos-api/validators"
+
+	"github.com/tealeg/xlsx/v3"
+)
+
+const (
+	templateDataSheet  = "Contactos"
+	templateHelpSheet  = "Instrucciones"
+	templateListsSheet = "Listas"
+	// templateRows filas de captura a las que se aplican las validaciones
+	templateRows = 10000
+)
+
+// digitCount fórmula de Excel que cuenta los dígitos de una celda no vacía. Las validaciones de
+// datos no admiten constantes de arreglo ({0,1,...}): los caracteres se recorren con
+// MID y ROW(INDIRECT(...)), que sí se permiten.
+func digitCount(ref string) string {
+	return fmt.Sprintf(`SUMPRODUCT(--ISNUMBER(--MID(%[1]s,ROW(INDIRECT("1:"&LEN(%[1]s))),1)))`, ref)
+}
+
+// templateRule validación personalizada de Excel para una columna de la hoja de captura
+type templateRule struct {
+	column  int    // Índice base 0
+	formula string // Relativa a la primera fila de datos (fila 2)
+	title   string
+	message string
+}
+
+// templateRules traduce las reglas del validador a validaciones de Excel
+func templateRules(rules validators.ValidatorConfig) []templateRule {
+	dominios := fmt.Sprintf("%s!$A$2:$A$%d", templateListsSheet, len(rules.DominiosCorreo)+1)
+
+	return []templateRule{
+		{
+			column: 0,
+			formula: fmt.Sprintf(`AND(%s=LEN(A2),VALUE(A2)>=%d,COUNTIF($A$2:$A$%d,A2)=1)`,
+				digitCount("A2"), rules.ClaveMinima, templateRows+1),
+			title:   "ClaveCliente",
+			message: fmt.Sprintf("Número entero mayor o igual a %d, sin repetir", rules.ClaveMinima),
+		},
+		{
+			column:  1,
+			formula: fmt.Sprintf(`AND(LEN(TRIM(B2))>0,%s=0)`, digitCount("B2")),
+			title:   "Nombre",
+			message: "Obligatorio, sin números",
+		},
+		{
+			// El dominio se busca en la lista de dominios permitidos de la hoja oculta
+			column: 2,
+			formula: fmt.Sprintf(`AND(ISNUMBER(FIND("@",C2)),COUNTIF(%s,MID(C2,FIND("@",C2)+1,255))>0)`,
+				dominios),
+			title:   "Correo",
+			message: fmt.Sprintf("usuario@dominio con un dominio permitido: %s", strings.Join(rules.DominiosCorreo, ", ")),
+		},
+		{
+			column:  3,
+			formula: fmt.Sprintf(`AND(LEN(D2)=%d,%s=%d)`, rules.TelefonoDigitos, digitCount("D2"), rules.TelefonoDigitos),
+			title:   "TelefonoContacto",
+			message: fmt.Sprintf("Exactamente %d dígitos, sin espacios ni guiones", rules.TelefonoDigitos),
+		},
+	}
+}
+
+// WriteImportTemplate genera la plantilla de importación: hoja de captura con encabezados,
+// formato de texto en clave y teléfono y validaciones de Excel derivadas de las reglas activas.
+// La hoja de captura va primero para que la plantilla llena se pueda importar tal cual.
+func WriteImportTemplate(w io.Writer, rules validators.ValidatorConfig) error {
+	file := xlsx.NewFile()
+
+	headerStyle := xlsx.NewStyle()
+	headerStyle.Font.Bold = true
+	headerStyle.ApplyFont = true
+
+	// 📄 Hoja de captura
+	data, err := file.AddSheet(templateDataSheet)
+	if err != nil {
+		return fmt.Errorf("error creando hoja: %w", err)
+	}
+
+	widths := []float64{14, 32, 32, 18}
+	for i, width := range widths {
+		col := xlsx.NewColForRange(i+1, i+1)
+		col.SetWidth(width)
+		if i == 0 || i == 3 {
+			// Texto: evita notación científica y ceros a la izquierda perdidos
+			col.SetType(xlsx.CellTypeString)
+		}
+		data.SetColParameters(col)
+	}
+
+	header := data.AddRow()
+	for _, name := range headersFor(Columns) {
+		cell := header.AddCell()
+		cell.SetString(name)
+		cell.SetStyle(headerStyle)
+	}
+
+	for _, rule := range templateRules(rules) {
+		dv := xlsx.NewDataValidation(1, rule.column, templateRows, rule.column, true)
+		dv.Type = "custom"
+		dv.Formula1 = rule.formula
+		title, message := rule.title, rule.message
+		dv.SetInput(&title, &message)
+		errorTitle, errorMessage := rule.title+" inválido", rule.message
+		dv.SetError(xlsx.StyleStop, &errorTitle, &errorMessage)
+		data.AddDataValidation(dv)
+	}
+
+	// 📋 Instrucciones con ejemplos
+	help, err := file.AddSheet(templateHelpSheet)
+	if err != nil {
+		return fmt.Errorf("error creando hoja: %w", err)
+	}
+	help.SetColWidth(1, 1, 20)
+	help.SetColWidth(2, 4, 32)
+
+	addRow := func(sheet *xlsx.Sheet, bold bool, values ...string) {
+		row := sheet.AddRow()
+		for _, value := range values {
+			cell := row.AddCell()
+			cell.SetString(value)
+			if bold {
+				cell.SetStyle(headerStyle)
+			}
+		}
+	}
+
+	addRow(help, true, "Columna", "Regla")
+	for _, rule := range templateRules(rules) {
+		addRow(help, false, rule.title, rule.message)
+	}
+	help.AddRow()
+	addRow(help, true, "Ejemplos (capture sus datos en la hoja "+templateDataSheet+")")
+	addRow(help, true, headersFor(Columns)...)
+	for _, example := range templateExamples(rules) {
+		addRow(help, false, example[:]...)
+	}
+
+	// 🔒 Listas usadas por las validaciones
+	lists, err := file.AddSheet(templateListsSheet)
+	if err != nil {
+		return fmt.Errorf("error creando hoja: %w", err)
+	}
+	lists.Hidden = true
+	addRow(lists, true, "DominiosCorreo")
+	for _, dominio := range rules.DominiosCorreo {
+		addRow(lists, false, dominio)
+	}
+
+	return file.Write(w)
+}
+
+// templateExamples filas de ejemplo válidas según las reglas activas
+func templateExamples(rules validators.ValidatorConfig) [][4]string {
+	telefono := func(prefix string) string {
+		digits := strings.Repeat(prefix, rules.TelefonoDigitos/len(prefix)+1)
+		return digits[:rules.TelefonoDigitos]
+	}
+	dominio := func(i int) string {
+		return rules.DominiosCorreo[i%len(rules.DominiosCorreo)]
+	}
+
+	return [][4]string{
+		{fmt.Sprint(rules.ClaveMinima), "María López", "maria.lopez@" + dominio(0), telefono("5512345678")},
+		{fmt.Sprint(rules.ClaveMinima + 1), "José Hernández", "jose.hernandez@" + dominio(1), telefono("3398765432")},
+	}
+}
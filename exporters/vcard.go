@@ -0,0 +1,109 @@
+// exporters/vcard.go
+package exporters
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"contactos-api/models"
+)
+
+// Versiones de vCard soportadas
+const (
+	VCardVersion3 = "3.0"
+	VCardVersion4 = "4.0"
+)
+
+// vcardLineLimit longitud máxima de línea en octetos antes de plegar (RFC 6350 §3.2)
+const vcardLineLimit = 75
+
+// vcardWriter escribe una tarjeta por contacto. La clave viaja en X-CLAVE-CLIENTE
+// para que la importación actualice el mismo contacto.
+type vcardWriter struct {
+	w       *bufio.Writer
+	version string
+}
+
+// NewVCardWriter crea un writer de vCard 3.0 o 4.0
+func NewVCardWriter(w io.Writer, version string) (Writer, error) {
+	if version == "" {
+		version = VCardVersion4
+	}
+	if version != VCardVersion3 && version != VCardVersion4 {
+		return nil, fmt.Errorf("versión de vCard '%s' no soportada. Use 3.0 o 4.0", version)
+	}
+	return &vcardWriter{w: bufio.NewWriter(w), version: version}, nil
+}
+
+func (vw *vcardWriter) Write(contacto models.Contacto) error {
+	clave := strconv.Itoa(contacto.ClaveCliente)
+
+	vw.line("BEGIN:VCARD")
+	vw.line("VERSION:" + vw.version)
+	vw.line("FN:" + escapeVCard(contacto.Nombre))
+	vw.line("N:" + nField(contacto.Nombre))
+	if contacto.Correo != "" {
+		if vw.version == VCardVersion3 {
+			vw.line("EMAIL;TYPE=INTERNET:" + escapeVCard(contacto.Correo))
+		} else {
+			vw.line("EMAIL:" + escapeVCard(contacto.Correo))
+		}
+	}
+	if contacto.TelefonoContacto != "" {
+		if vw.version == VCardVersion3 {
+			vw.line("TEL;TYPE=CELL:" + escapeVCard(contacto.TelefonoContacto))
+		} else {
+			vw.line("TEL;VALUE=uri;TYPE=cell:tel:" + contacto.TelefonoContacto)
+		}
+	}
+	vw.line("UID:contactos-api-" + clave)
+	vw.line("X-CLAVE-CLIENTE:" + clave)
+	vw.line("END:VCARD")
+
+	if vw.w.Buffered() > 32<<10 {
+		return vw.w.Flush()
+	}
+	return nil
+}
+
+func (vw *vcardWriter) Close() error {
+	return vw.w.Flush()
+}
+
+// line escribe una línea terminada en CRLF, plegándola a 75 octetos sin partir runas
+func (vw *vcardWriter) line(content string) {
+	limit := vcardLineLimit
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(content[cut]) {
+			cut--
+		}
+		vw.w.WriteString(content[:cut])
+		vw.w.WriteString("\r\n ")
+		content = content[cut:]
+		limit = vcardLineLimit - 1 // El espacio de continuación cuenta
+	}
+	vw.w.WriteString(content)
+	vw.w.WriteString("\r\n")
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// nField arma N:apellidos;nombre;;; tomando la primera palabra como nombre
+func nField(nombre string) string {
+	partes := strings.Fields(nombre)
+	if len(partes) == 0 {
+		return ";;;;"
+	}
+	return escapeVCard(strings.Join(partes[1:], " ")) + ";" + escapeVCard(partes[0]) + ";;;"
+}
+
+// escapeVCard escapa texto según RFC 6350 (\\, \n, \, y \;)
+func escapeVCard(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "\r\n", `\n`, "\n", `\n`, ",", `\,`, ";", `\;`).Replace(value)
+}
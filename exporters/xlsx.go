@@ -0,0 +1,120 @@
+// exporters/xlsx.go
+package exporters
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strconv"
+
+	"contactos-api/models"
+)
+
+// xlsxWriter genera un .xlsx mínimo escribiendo la hoja directamente en el zip de salida,
+// sin construir el libro en memoria. Usa cadenas inline para no necesitar sharedStrings.
+type xlsxWriter struct {
+	zip     *zip.Writer
+	sheet   *bufio.Writer
+	columns []string
+	row     int
+}
+
+// Partes fijas del paquete OOXML
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Contactos" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`
+	xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetEnd = `</sheetData></worksheet>`
+)
+
+func newXLSXWriter(w io.Writer, columns []string) (*xlsxWriter, error) {
+	zw := zip.NewWriter(w)
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+	}
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	// La hoja es la última parte: se escribe en streaming hasta Close
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	xw := &xlsxWriter{zip: zw, sheet: bufio.NewWriter(f), columns: columns}
+	xw.sheet.WriteString(xlsxSheetStart)
+
+	headers := headersFor(columns)
+	xw.writeRow(func(i int) (string, bool) { return headers[i], false })
+
+	return xw, nil
+}
+
+func (xw *xlsxWriter) Write(contacto models.Contacto) error {
+	xw.writeRow(func(i int) (string, bool) {
+		column := xw.columns[i]
+		return columnValue(contacto, column), column == "claveCliente"
+	})
+
+	if xw.sheet.Buffered() > 32<<10 {
+		return xw.sheet.Flush()
+	}
+	return nil
+}
+
+// writeRow escribe una fila; value retorna el texto de la celda i y si es numérica.
+// Teléfonos y demás texto van como inlineStr para conservar ceros a la izquierda.
+func (xw *xlsxWriter) writeRow(value func(i int) (string, bool)) {
+	xw.row++
+	rowRef := strconv.Itoa(xw.row)
+
+	xw.sheet.WriteString(`<row r="` + rowRef + `">`)
+	for i := range xw.columns {
+		text, numeric := value(i)
+		ref := columnLetter(i) + rowRef
+		if numeric {
+			xw.sheet.WriteString(`<c r="` + ref + `"><v>` + text + `</v></c>`)
+			continue
+		}
+		xw.sheet.WriteString(`<c r="` + ref + `" t="inlineStr"><is><t xml:space="preserve">`)
+		xml.EscapeText(xw.sheet, []byte(text))
+		xw.sheet.WriteString(`</t></is></c>`)
+	}
+	xw.sheet.WriteString(`</row>`)
+}
+
+func (xw *xlsxWriter) Close() error {
+	xw.sheet.WriteString(xlsxSheetEnd)
+	if err := xw.sheet.Flush(); err != nil {
+		return err
+	}
+	return xw.zip.Close()
+}
+
+// columnLetter letra de columna de Excel para un índice base 0 (A..Z, AA..)
+func columnLetter(i int) string {
+	letters := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		letters = string(rune('A'+(i-1)%26)) + letters
+	}
+	return letters
+}
@@ -2,20 +2,23 @@ module contactos-api
 
 go 1.24.3
 
+require (
+	github.com/gorilla/mux v1.8.1
+	github.com/plandem/xlsx v1.0.4
+	github.com/rs/cors v1.11.1
+	github.com/tealeg/xlsx/v3 v3.3.13
+	golang.org/x/text v0.3.8
+)
+
 require (
 	github.com/frankban/quicktest v1.14.6 // indirect
 	github.com/google/btree v1.0.0 // indirect
 	github.com/google/go-cmp v0.5.9 // indirect
-	github.com/gorilla/mux v1.8.1 // indirect
 	github.com/kr/pretty v0.3.1 // indirect
 	github.com/kr/text v0.2.0 // indirect
 	github.com/peterbourgon/diskv/v3 v3.0.1 // indirect
 	github.com/plandem/ooxml v1.1.2 // indirect
-	github.com/plandem/xlsx v1.0.4 // indirect
 	github.com/rogpeppe/fastuuid v1.2.0 // indirect
 	github.com/rogpeppe/go-internal v1.9.0 // indirect
-	github.com/rs/cors v1.11.1 // indirect
 	github.com/shabbyrobe/xmlwriter v0.0.0-20200208144257-9fca06d00ffa // indirect
-	github.com/tealeg/xlsx/v3 v3.3.13 // indirect
-	golang.org/x/text v0.3.8 // indirect
 )
@@ -0,0 +1,92 @@
+// handlers/contacto_events_handler.go
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"contactos-api/models"
+)
+
+const (
+	// sseHeartbeat intervalo de comentarios keep-alive para proxies y balanceadores
+	sseHeartbeat = 15 * time.Second
+	// sseRetryMillis espera sugerida al cliente antes de reconectar
+	sseRetryMillis = 3000
+)
+
+// StreamEvents maneja GET /api/contactos/events (Server-Sent Events).
+// Reanuda desde el header Last-Event-ID o el parámetro lastEventId.
+func (h *ContactoHandler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("lastEventId")
+	}
+	lastEventID, err := strconv.ParseUint(lastID, 10, 64)
+	resume := lastID != "" && err == nil
+
+	sub := h.service.SubscribeEvents(lastEventID, resume)
+	defer sub.Close()
+
+	// El stream no debe cortarse por el WriteTimeout del servidor
+	rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetryMillis)
+	if sub.Missed {
+		writeSSE(w, models.ChangeEvent{
+			Type:      models.EventResync,
+			Claves:    []int{},
+			Timestamp: time.Now().Format(time.RFC3339),
+		})
+	}
+	for _, event := range sub.Backlog {
+		writeSSE(w, event)
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-sub.Events:
+			if !ok {
+				// Desconectado por lento: el cliente reconecta con Last-Event-ID
+				return
+			}
+			writeSSE(w, event)
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeSSE escribe un evento en formato SSE; los eventos sin id no mueven Last-Event-ID
+func writeSSE(w http.ResponseWriter, event models.ChangeEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	if event.ID > 0 {
+		fmt.Fprintf(w, "id: %d\n", event.ID)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+}
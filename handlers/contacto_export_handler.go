@@ -0,0 +1,194 @@
+// handlers/contacto_export_handler.go
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"contactos-api/exporters"
+	"contactos-api/models"
+	"contactos-api/services"
+	"contactos-api/utils"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// exportFlushEvery filas entre cada volcado al cliente
+	exportFlushEvery = 1000
+	// exportWriteWindow plazo de escritura que se renueva en cada volcado
+	exportWriteWindow = 30 * time.Second
+)
+
+// ExportContactos maneja GET /api/contactos/export?format=csv|json|ndjson|xlsx|vcf&q=...&fields=...
+func (h *ContactoHandler) ExportContactos(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	format := query.Get("format")
+	if format == "" {
+		format = exporters.FormatCSV
+	}
+	if !exporters.Supported(format) {
+		utils.ValidationErrorResponse(w, []models.ErrorResponse{{
+			Campo:   "format",
+			Mensaje: fmt.Sprintf("Formato '%s' no soportado. Use csv, json, ndjson, xlsx o vcf", format),
+		}})
+		return
+	}
+
+	// vCard: versión 3.0 o 4.0 (default 4.0)
+	version := query.Get("version")
+	if format == exporters.FormatVCF && version != "" && version != exporters.VCardVersion3 && version != exporters.VCardVersion4 {
+		utils.ValidationErrorResponse(w, []models.ErrorResponse{{
+			Campo:   "version",
+			Mensaje: fmt.Sprintf("Versión de vCard '%s' no soportada. Use 3.0 o 4.0", version),
+		}})
+		return
+	}
+
+	// fields= es el mismo recorte de los listados; columns= se conserva por compatibilidad
+	param := "columns"
+	if query.Get("fields") != "" {
+		if query.Get("columns") != "" {
+			utils.ValidationErrorResponse(w, []models.ErrorResponse{{Campo: "fields", Mensaje: "Use fields o columns, no ambos"}})
+			return
+		}
+		param = "fields"
+	}
+	columns, err := exporters.ParseColumns(query.Get(param))
+	if err != nil {
+		utils.ValidationErrorResponse(w, []models.ErrorResponse{{Campo: param, Mensaje: err.Error()}})
+		return
+	}
+
+	opts, errores := parseSearchOptions(query)
+	if len(errores) > 0 {
+		utils.ValidationErrorResponse(w, errores)
+		return
+	}
+	rc := http.NewResponseController(w)
+
+	// El writer se abre con el primer contacto (o al final si no hay): si la búsqueda
+	// quedó incompleta todavía se puede responder con error en lugar de un archivo truncado
+	var writer exporters.Writer
+	open := func() error {
+		fileName := fmt.Sprintf("contactos_%s.%s", time.Now().Format("20060102_150405"), format)
+		w.Header().Set("Content-Type", exporters.ContentType(format))
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+
+		var err error
+		if format == exporters.FormatVCF {
+			writer, err = exporters.NewVCardWriter(w, version)
+		} else {
+			writer, err = exporters.New(format, w, columns)
+		}
+		if err != nil {
+			return fmt.Errorf("error iniciando exportación: %w", err)
+		}
+		return nil
+	}
+
+	// Los errores a mitad del stream ya no pueden cambiar el status: solo se registran
+	rows := 0
+	err = h.service.ExportContactos(opts, func(contacto models.Contacto) error {
+		if writer == nil {
+			if err := open(); err != nil {
+				return err
+			}
+		}
+		if err := writer.Write(contacto); err != nil {
+			return err
+		}
+		rows++
+		if rows%exportFlushEvery == 0 {
+			rc.SetWriteDeadline(time.Now().Add(exportWriteWindow))
+			if err := rc.Flush(); err != nil {
+				return err
+			}
+		}
+		return r.Context().Err()
+	})
+	if errors.Is(err, services.ErrPartialSearch) {
+		utils.ValidationErrorResponse(w, []models.ErrorResponse{{Campo: "q", Mensaje: err.Error()}})
+		return
+	}
+	if err == nil && writer == nil {
+		err = open()
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		fmt.Printf("⚠️ Exportación interrumpida tras %d filas: %v\n", rows, err)
+	}
+}
+
+// ExportContactoVCard maneja GET /api/contactos/{clave}/vcard?version=3.0|4.0
+func (h *ContactoHandler) ExportContactoVCard(w http.ResponseWriter, r *http.Request) {
+	claveStr := mux.Vars(r)["clave"]
+	clave, err := h.extractNumericKey(claveStr)
+	if err != nil {
+		utils.BadRequestResponse(w, fmt.Sprintf("No se pudo extraer clave numérica válida de '%s': %v", claveStr, err))
+		return
+	}
+
+	contacto, err := h.service.GetContactoByID(clave)
+	if err != nil {
+		utils.NotFoundResponse(w, fmt.Sprintf("Contacto con clave %d no encontrado", clave))
+		return
+	}
+
+	// Generar en memoria: una sola tarjeta, y así los errores aún pueden responder JSON
+	var buf bytes.Buffer
+	writer, err := exporters.NewVCardWriter(&buf, r.URL.Query().Get("version"))
+	if err != nil {
+		utils.ValidationErrorResponse(w, []models.ErrorResponse{{Campo: "version", Mensaje: err.Error()}})
+		return
+	}
+	if err := writer.Write(*contacto); err != nil {
+		utils.InternalServerErrorResponse(w, "Error generando vCard: "+err.Error())
+		return
+	}
+	writer.Close()
+
+	w.Header().Set("Content-Type", exporters.ContentType(exporters.FormatVCF))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"contacto_%d.vcf\"", clave))
+	w.Write(buf.Bytes())
+}
+
+// ExportInvalidDataWorkbook maneja GET /api/contactos/invalid-data/export.xlsx
+func (h *ContactoHandler) ExportInvalidDataWorkbook(w http.ResponseWriter, r *http.Request) {
+	// Generar completo en memoria: si falla todavía se puede responder con error
+	var buf bytes.Buffer
+	if err := h.service.ExportErrorWorkbook(&buf); err != nil {
+		utils.InternalServerErrorResponse(w, "Error generando libro de errores: "+err.Error())
+		return
+	}
+
+	fileName := fmt.Sprintf("contactos_errores_%s.xlsx", time.Now().Format("20060102_150405"))
+	w.Header().Set("Content-Type", exporters.ContentType(exporters.FormatXLSX))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	w.Header().Set("Content-Length", fmt.Sprint(buf.Len()))
+	if _, err := buf.WriteTo(w); err != nil {
+		fmt.Printf("⚠️ Error enviando libro de errores: %v\n", err)
+	}
+}
+
+// GetImportTemplate maneja GET /api/contactos/template.xlsx
+func (h *ContactoHandler) GetImportTemplate(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	if err := h.service.WriteImportTemplate(&buf); err != nil {
+		utils.InternalServerErrorResponse(w, "Error generando plantilla: "+err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", exporters.ContentType(exporters.FormatXLSX))
+	w.Header().Set("Content-Disposition", `attachment; filename="plantilla_contactos.xlsx"`)
+	w.Header().Set("Content-Length", fmt.Sprint(buf.Len()))
+	if _, err := buf.WriteTo(w); err != nil {
+		fmt.Printf("⚠️ Error enviando plantilla: %v\n", err)
+	}
+}
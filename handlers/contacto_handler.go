@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"regexp"
@@ -94,17 +96,27 @@ func (h *ContactoHandler) GetContactosPaginated(w http.ResponseWriter, r *http.R
 		}
 	}
 	
-	// Obtener término de búsqueda opcional
-	search := query.Get("search")
+	// Criterios de búsqueda opcionales (search, mode, minScore)
+	opts, errores := parseSearchOptions(query)
+	fields, fieldErrores := parseFields(query, contactoFields)
+	errores = append(errores, fieldErrores...)
+	if len(errores) > 0 {
+		utils.ValidationErrorResponse(w, errores)
+		return
+	}
 	
 	// Llamar al servicio
-	result, err := h.service.GetContactosPaginated(page, size, search)
+	result, errores, err := h.searchPage(query, opts, page, size)
 	if err != nil {
 		utils.InternalServerErrorResponse(w, "Error obteniendo contactos paginados: "+err.Error())
 		return
 	}
+	if len(errores) > 0 {
+		utils.ValidationErrorResponse(w, errores)
+		return
+	}
 	
-	utils.SuccessResponse(w, result)
+	utils.SuccessResponse(w, fields.page(result))
 }
 
 // SearchContactosPaginated maneja GET /api/contactos/search
@@ -138,14 +150,89 @@ func (h *ContactoHandler) SearchContactosPaginated(w http.ResponseWriter, r *htt
 		}
 	}
 	
+	// Modo de búsqueda (substring o fuzzy) y similitud mínima
+	opts, errores := parseSearchOptions(query)
+	fields, fieldErrores := parseFields(query, contactoFields)
+	errores = append(errores, fieldErrores...)
+	if len(errores) > 0 {
+		utils.ValidationErrorResponse(w, errores)
+		return
+	}
+	
 	// Llamar al servicio
-	result, err := h.service.SearchContactosPaginated(searchTerm, page, size)
+	result, errores, err := h.searchPage(query, opts, page, size)
 	if err != nil {
 		utils.InternalServerErrorResponse(w, "Error buscando contactos: "+err.Error())
 		return
 	}
+	if len(errores) > 0 {
+		utils.ValidationErrorResponse(w, errores)
+		return
+	}
 	
-	utils.SuccessResponse(w, result)
+	utils.SuccessResponse(w, fields.page(result))
+}
+
+// searchPage pagina por número de página o, si viene el parámetro "cursor" (vacío para la
+// primera página), por cursor estable ante altas y bajas entre peticiones
+func (h *ContactoHandler) searchPage(query url.Values, opts services.SearchOptions, page, size int) (*services.PaginatedResult, []models.ErrorResponse, error) {
+	if !query.Has("cursor") {
+		result, err := h.service.SearchPaginated(opts, page, size)
+		return result, nil, err
+	}
+	
+	if query.Get("page") != "" {
+		return nil, []models.ErrorResponse{{Campo: "cursor", Mensaje: "Use page o cursor, no ambos"}}, nil
+	}
+	
+	result, err := h.service.SearchCursor(opts, query.Get("cursor"), size)
+	if errors.Is(err, services.ErrInvalidCursor) {
+		return nil, []models.ErrorResponse{{Campo: "cursor", Mensaje: err.Error()}}, nil
+	}
+	return result, nil, err
+}
+
+// SuggestContactos maneja GET /api/contactos/suggest?prefix=...&limit=10
+func (h *ContactoHandler) SuggestContactos(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var errores []models.ErrorResponse
+	prefix := query.Get("prefix")
+	switch {
+	case strings.TrimSpace(prefix) == "":
+		errores = append(errores, models.ErrorResponse{Campo: "prefix", Mensaje: "El prefijo es requerido"})
+	case len(prefix) > services.MaxSuggestPrefix:
+		errores = append(errores, models.ErrorResponse{
+			Campo:   "prefix",
+			Mensaje: fmt.Sprintf("El prefijo supera los %d caracteres", services.MaxSuggestPrefix),
+		})
+	}
+
+	limit := services.DefaultSuggestLimit
+	if limitStr := query.Get("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		if err != nil || l < 1 || l > services.MaxSuggestLimit {
+			errores = append(errores, models.ErrorResponse{
+				Campo:   "limit",
+				Mensaje: fmt.Sprintf("limit debe ser un número entre 1 y %d", services.MaxSuggestLimit),
+			})
+		} else {
+			limit = l
+		}
+	}
+
+	if len(errores) > 0 {
+		utils.ValidationErrorResponse(w, errores)
+		return
+	}
+
+	suggestions, err := h.service.Suggest(prefix, limit)
+	if err != nil {
+		utils.InternalServerErrorResponse(w, "Error obteniendo sugerencias: "+err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, suggestions)
 }
 
 // GetContactosCount maneja GET /api/contactos/count
@@ -163,12 +250,18 @@ func (h *ContactoHandler) GetContactosCount(w http.ResponseWriter, r *http.Reque
 
 // GetAllContactos maneja GET /api/contactos
 func (h *ContactoHandler) GetAllContactos(w http.ResponseWriter, r *http.Request) {
+	fields, errores := parseFields(r.URL.Query(), contactoFields)
+	if len(errores) > 0 {
+		utils.ValidationErrorResponse(w, errores)
+		return
+	}
+	
 	contactos, err := h.service.GetAllContactos()
 	if err != nil {
 		utils.InternalServerErrorResponse(w, "Error obteniendo contactos")
 		return
 	}
-	utils.SuccessResponse(w, contactos)
+	utils.SuccessResponse(w, fields.project(contactos))
 }
 
 // ✅ GetContactoByID maneja GET /api/contactos/{clave} - MODIFICADO para claves flexibles
@@ -276,6 +369,22 @@ func (h *ContactoHandler) DeleteContacto(w http.ResponseWriter, r *http.Request)
 func (h *ContactoHandler) SearchContactos(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
 
+	// q usa el lenguaje de consulta (p. ej. correo:*@gmail.com AND NOT clave:1..100)
+	if query.Get("q") != "" {
+		h.queryContactos(w, query)
+		return
+	}
+
+	fields, errores := parseFields(query, contactoFields)
+	sortSpec, err := services.ParseSortSpec(query.Get("sort"))
+	if err != nil {
+		errores = append(errores, models.ErrorResponse{Campo: "sort", Mensaje: err.Error()})
+	}
+	if len(errores) > 0 {
+		utils.ValidationErrorResponse(w, errores)
+		return
+	}
+
 	criteria := &models.ContactoDTO{
 		ClaveCliente: query.Get("claveCliente"),
 		Nombre:       query.Get("nombre"),
@@ -294,7 +403,44 @@ func (h *ContactoHandler) SearchContactos(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	utils.SuccessResponse(w, contactos)
+	utils.SuccessResponse(w, fields.project(h.service.SortContactos(contactos, sortSpec)))
+}
+
+// queryContactos responde /buscar?q= con todos los contactos que cumplen la consulta
+func (h *ContactoHandler) queryContactos(w http.ResponseWriter, query url.Values) {
+	for _, campo := range []string{"claveCliente", "nombre", "correo", "telefono"} {
+		if query.Get(campo) != "" {
+			utils.BadRequestResponse(w, fmt.Sprintf("Use 'q' o los filtros por campo, no ambos (se recibió '%s')", campo))
+			return
+		}
+	}
+	if query.Get("mode") == "" {
+		query.Set("mode", services.SearchModeQuery)
+	}
+
+	opts, errores := parseSearchOptions(query)
+	fields, fieldErrores := parseFields(query, contactoFields)
+	errores = append(errores, fieldErrores...)
+	if len(errores) > 0 {
+		utils.ValidationErrorResponse(w, errores)
+		return
+	}
+
+	contactos := []models.Contacto{}
+	err := h.service.ExportContactos(opts, func(contacto models.Contacto) error {
+		contactos = append(contactos, contacto)
+		return nil
+	})
+	if errors.Is(err, services.ErrPartialSearch) {
+		utils.ValidationErrorResponse(w, []models.ErrorResponse{{Campo: "q", Mensaje: err.Error()}})
+		return
+	}
+	if err != nil {
+		utils.InternalServerErrorResponse(w, "Error buscando contactos: "+err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, fields.project(contactos))
 }
 
 // ✅ GetContactoStats maneja GET /api/contactos/stats (CORREGIDO)
@@ -312,7 +458,15 @@ func (h *ContactoHandler) GetContactoStats(w http.ResponseWriter, r *http.Reques
 
 // GetExcelValidationReport maneja GET /api/contactos/validation
 func (h *ContactoHandler) GetExcelValidationReport(w http.ResponseWriter, r *http.Request) {
-	report, err := h.service.GetExcelValidationReport()
+	// Cantidad de errores comunes en el resumen (default: 10)
+	topErrors := 0
+	if topStr := r.URL.Query().Get("top"); topStr != "" {
+		if t, err := strconv.Atoi(topStr); err == nil && t > 0 {
+			topErrors = t
+		}
+	}
+	
+	report, err := h.service.GetExcelValidationReport(topErrors)
 	if err != nil {
 		utils.InternalServerErrorResponse(w, "Error obteniendo reporte: "+err.Error())
 		return
@@ -322,6 +476,17 @@ func (h *ContactoHandler) GetExcelValidationReport(w http.ResponseWriter, r *htt
 
 // ReloadExcel maneja POST /api/contactos/reload
 func (h *ContactoHandler) ReloadExcel(w http.ResponseWriter, r *http.Request) {
+	// Recargar en segundo plano y responder con el trabajo (async=false espera el resultado)
+	if runInBackground(w, r.URL.Query().Get("async")) {
+		job, err := h.service.StartReloadJob()
+		if err != nil {
+			utils.InternalServerErrorResponse(w, "Error iniciando recarga: "+err.Error())
+			return
+		}
+		utils.AcceptedResponse(w, job, jobLocation(job.ID))
+		return
+	}
+	
 	report, err := h.service.ReloadExcel()
 	if err != nil {
 		utils.InternalServerErrorResponse(w, "Error recargando Excel: "+err.Error())
@@ -330,9 +495,50 @@ func (h *ContactoHandler) ReloadExcel(w http.ResponseWriter, r *http.Request) {
 	utils.SuccessResponse(w, report)
 }
 
+// GetValidationDiff maneja GET /api/contactos/validation/diff
+func (h *ContactoHandler) GetValidationDiff(w http.ResponseWriter, r *http.Request) {
+	diff, err := h.service.GetValidationDiff()
+	if err != nil {
+		utils.NotFoundResponse(w, "Diff no disponible: "+err.Error())
+		return
+	}
+	utils.SuccessResponse(w, diff)
+}
+
+// GetPreviousValidationReport maneja GET /api/contactos/validation/previous
+func (h *ContactoHandler) GetPreviousValidationReport(w http.ResponseWriter, r *http.Request) {
+	report, err := h.service.GetPreviousValidationReport()
+	if err != nil {
+		utils.NotFoundResponse(w, "Reporte previo no disponible: "+err.Error())
+		return
+	}
+	utils.SuccessResponse(w, report)
+}
+
+// TestValidationRules maneja POST /api/contactos/validation/rules/test
+func (h *ContactoHandler) TestValidationRules(w http.ResponseWriter, r *http.Request) {
+	var request models.RuleTestRequest
+	if err := utils.ParseJSON(r, &request); err != nil {
+		utils.BadRequestResponse(w, "JSON inválido")
+		return
+	}
+
+	result, errores, err := h.service.TestRules(&request)
+	if err != nil {
+		utils.InternalServerErrorResponse(w, "Error probando reglas: "+err.Error())
+		return
+	}
+	if len(errores) > 0 {
+		utils.ValidationErrorResponse(w, errores)
+		return
+	}
+
+	utils.SuccessResponse(w, result)
+}
+
 // GetValidationErrors maneja GET /api/contactos/errors
 func (h *ContactoHandler) GetValidationErrors(w http.ResponseWriter, r *http.Request) {
-	report, err := h.service.GetExcelValidationReport()
+	report, err := h.service.GetExcelValidationReport(0)
 	if err != nil {
 		utils.InternalServerErrorResponse(w, "Error obteniendo errores: "+err.Error())
 		return
@@ -342,23 +548,35 @@ func (h *ContactoHandler) GetValidationErrors(w http.ResponseWriter, r *http.Req
 
 // GetContactosConEstadoValidacion maneja GET /api/contactos/con-validacion
 func (h *ContactoHandler) GetContactosConEstadoValidacion(w http.ResponseWriter, r *http.Request) {
+	fields, errores := parseFields(r.URL.Query(), contactoFields)
+	if len(errores) > 0 {
+		utils.ValidationErrorResponse(w, errores)
+		return
+	}
+
 	contactos, err := h.service.GetAllContactos()
 	if err != nil {
 		utils.InternalServerErrorResponse(w, "Error obteniendo contactos: "+err.Error())
 		return
 	}
-	utils.SuccessResponse(w, contactos)
+	utils.SuccessResponse(w, fields.project(contactos))
 }
 
 // ✅ GetInvalidContactsForCorrection maneja GET /api/contactos/invalid-data (CORREGIDO)
 func (h *ContactoHandler) GetInvalidContactsForCorrection(w http.ResponseWriter, r *http.Request) {
+	fields, errores := parseFields(r.URL.Query(), rowDataFields)
+	if len(errores) > 0 {
+		utils.ValidationErrorResponse(w, errores)
+		return
+	}
+	
 	data, err := h.service.GetInvalidContactsForCorrection()
 	if err != nil {
 		utils.InternalServerErrorResponse(w, "Error obteniendo datos inválidos: "+err.Error())
 		return
 	}
 	
-	utils.SuccessResponse(w, data)
+	utils.SuccessResponse(w, fields.project(data))
 }
 
 // HealthCheck maneja GET /api/health
@@ -0,0 +1,108 @@
+// handlers/contacto_import_handler.go
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"contactos-api/services"
+	"contactos-api/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// maxImportSize tamaño máximo del archivo subido (50MB)
+const maxImportSize = 50 << 20
+
+// ImportContactos maneja POST /api/contactos/import (multipart, campo "file")
+func (h *ContactoHandler) ImportContactos(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		utils.BadRequestResponse(w, "Formulario multipart inválido o archivo demasiado grande: "+err.Error())
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		utils.BadRequestResponse(w, "Campo 'file' (archivo .xlsx, .csv o .vcf) es requerido")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		utils.BadRequestResponse(w, "Error leyendo archivo: "+err.Error())
+		return
+	}
+
+	// Modo de importación (default: upsert)
+	mode := r.FormValue("mode")
+	if mode == "" {
+		mode = services.ImportModeUpsert
+	}
+
+	// Dry-run: solo calcular el plan de cambios
+	if dryRun, _ := strconv.ParseBool(r.FormValue("dryRun")); dryRun {
+		preview, errores, err := h.service.PreviewImport(header.Filename, data, mode)
+		if err != nil {
+			utils.InternalServerErrorResponse(w, "Error generando vista previa: "+err.Error())
+			return
+		}
+		if len(errores) > 0 {
+			utils.ValidationErrorResponse(w, errores)
+			return
+		}
+		utils.SuccessResponse(w, preview)
+		return
+	}
+
+	// Importar en segundo plano y responder con el trabajo (async=false espera el resultado)
+	if runInBackground(w, r.FormValue("async")) {
+		job, errores, err := h.service.StartImportJob(header.Filename, data, mode)
+		if err != nil {
+			utils.InternalServerErrorResponse(w, "Error iniciando importación: "+err.Error())
+			return
+		}
+		if len(errores) > 0 {
+			utils.ValidationErrorResponse(w, errores)
+			return
+		}
+		utils.AcceptedResponse(w, job, jobLocation(job.ID))
+		return
+	}
+
+	result, errores, err := h.service.ImportContactos(header.Filename, data, mode)
+	if err != nil {
+		utils.InternalServerErrorResponse(w, "Error importando contactos: "+err.Error())
+		return
+	}
+
+	if len(errores) > 0 {
+		utils.ValidationErrorResponse(w, errores)
+		return
+	}
+
+	utils.SuccessResponse(w, result)
+}
+
+// ApplyImportPreview maneja POST /api/contactos/import/{token}/apply
+func (h *ContactoHandler) ApplyImportPreview(w http.ResponseWriter, r *http.Request) {
+	token := mux.Vars(r)["token"]
+
+	result, err := h.service.ApplyImportPreview(token)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrPreviewNotFound):
+			utils.NotFoundResponse(w, err.Error())
+		case errors.Is(err, services.ErrPreviewStale):
+			utils.ConflictResponse(w, err.Error())
+		default:
+			utils.InternalServerErrorResponse(w, "Error aplicando importación: "+err.Error())
+		}
+		return
+	}
+
+	utils.SuccessResponse(w, result)
+}
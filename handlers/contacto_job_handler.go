@@ -0,0 +1,77 @@
+// handlers/contacto_job_handler.go
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"contactos-api/services"
+	"contactos-api/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// jobLocation ruta de consulta del estado de un trabajo
+func jobLocation(id string) string {
+	return "/api/contactos/jobs/" + id
+}
+
+// runInBackground importaciones y recargas corren como trabajo salvo async=false; en ese caso
+// se quita el plazo de escritura del servidor para que un archivo grande no corte la respuesta
+func runInBackground(w http.ResponseWriter, value string) bool {
+	if async, err := strconv.ParseBool(value); err == nil && !async {
+		http.NewResponseController(w).SetWriteDeadline(time.Time{})
+		return false
+	}
+	return true
+}
+
+// ListJobs maneja GET /api/contactos/jobs
+func (h *ContactoHandler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	utils.SuccessResponse(w, h.service.ListJobs())
+}
+
+// GetJob maneja GET /api/contactos/jobs/{id}
+func (h *ContactoHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	job, err := h.service.GetJob(mux.Vars(r)["id"])
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+	utils.SuccessResponse(w, job)
+}
+
+// CancelJob maneja POST /api/contactos/jobs/{id}/cancel
+func (h *ContactoHandler) CancelJob(w http.ResponseWriter, r *http.Request) {
+	job, err := h.service.CancelJob(mux.Vars(r)["id"])
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+	utils.AcceptedResponse(w, job, jobLocation(job.ID))
+}
+
+// GetJobResult maneja GET /api/contactos/jobs/{id}/report
+func (h *ContactoHandler) GetJobResult(w http.ResponseWriter, r *http.Request) {
+	result, err := h.service.GetJobResult(mux.Vars(r)["id"])
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+	utils.SuccessResponse(w, result)
+}
+
+// writeJobError traduce los errores de trabajos a códigos HTTP
+func writeJobError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrJobNotFound):
+		utils.NotFoundResponse(w, err.Error())
+	case errors.Is(err, services.ErrJobNotFinished), errors.Is(err, services.ErrJobFinished),
+		errors.Is(err, services.ErrJobFailed):
+		utils.ConflictResponse(w, err.Error())
+	default:
+		utils.InternalServerErrorResponse(w, err.Error())
+	}
+}
@@ -0,0 +1,76 @@
+// handlers/contacto_transform_handler.go
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"contactos-api/models"
+	"contactos-api/services"
+	"contactos-api/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// TransformContactos maneja POST /api/contactos/transform (dryRun=true para la vista previa)
+func (h *ContactoHandler) TransformContactos(w http.ResponseWriter, r *http.Request) {
+	var request models.TransformRequest
+	if err := utils.ParseJSON(r, &request); err != nil {
+		utils.BadRequestResponse(w, "JSON inválido")
+		return
+	}
+
+	// El filtro usa los mismos criterios que /search
+	values := url.Values{}
+	if filter := request.Filter; filter != nil {
+		values.Set("q", filter.Q)
+		values.Set("mode", filter.Mode)
+		values.Set("field", filter.Field)
+		if filter.MinScore != 0 {
+			values.Set("minScore", strconv.FormatFloat(filter.MinScore, 'f', -1, 64))
+		}
+	}
+	filter, errores := parseSearchOptions(values)
+	if len(errores) > 0 {
+		for i := range errores {
+			errores[i].Campo = "filter." + errores[i].Campo
+		}
+		utils.ValidationErrorResponse(w, errores)
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dryRun"))
+	result, errores, err := h.service.TransformContactos(&request, filter, dryRun)
+	if err != nil {
+		utils.InternalServerErrorResponse(w, "Error transformando contactos: "+err.Error())
+		return
+	}
+	if len(errores) > 0 {
+		utils.ValidationErrorResponse(w, errores)
+		return
+	}
+
+	utils.SuccessResponse(w, result)
+}
+
+// UndoTransform maneja POST /api/contactos/transform/{id}/undo
+func (h *ContactoHandler) UndoTransform(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	result, err := h.service.UndoTransform(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUndoNotFound):
+			utils.NotFoundResponse(w, err.Error())
+		case errors.Is(err, services.ErrUndoStale):
+			utils.ConflictResponse(w, err.Error())
+		default:
+			utils.InternalServerErrorResponse(w, "Error deshaciendo transformación: "+err.Error())
+		}
+		return
+	}
+
+	utils.SuccessResponse(w, result)
+}
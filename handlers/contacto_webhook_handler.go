@@ -0,0 +1,101 @@
+// handlers/contacto_webhook_handler.go
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"contactos-api/models"
+	"contactos-api/services"
+	"contactos-api/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// ListWebhooks maneja GET /api/contactos/webhooks
+func (h *ContactoHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	subscriptions, err := h.service.ListWebhooks()
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	utils.SuccessResponse(w, subscriptions)
+}
+
+// CreateWebhook maneja POST /api/contactos/webhooks
+func (h *ContactoHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var request models.WebhookRequest
+	if err := utils.ParseJSON(r, &request); err != nil {
+		utils.BadRequestResponse(w, "JSON inválido")
+		return
+	}
+
+	subscription, errores, err := h.service.CreateWebhook(&request)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	if len(errores) > 0 {
+		utils.ValidationErrorResponse(w, errores)
+		return
+	}
+
+	utils.CreatedResponse(w, subscription)
+}
+
+// DeleteWebhook maneja DELETE /api/contactos/webhooks/{id}
+func (h *ContactoHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if err := h.service.DeleteWebhook(id); err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	utils.SuccessResponse(w, map[string]interface{}{
+		"message": "Suscripción eliminada exitosamente",
+		"id":      id,
+	})
+}
+
+// ListWebhookDeliveries maneja GET /api/contactos/webhooks/deliveries?status=failed|pending|all
+func (h *ContactoHandler) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = models.DeliveryStatusFailed
+	case "all":
+		status = ""
+	case models.DeliveryStatusFailed, models.DeliveryStatusPending:
+	default:
+		utils.BadRequestResponse(w, "Parámetro 'status' inválido. Use failed, pending o all")
+		return
+	}
+
+	deliveries, err := h.service.ListWebhookDeliveries(status)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	utils.SuccessResponse(w, deliveries)
+}
+
+// RetryWebhookDelivery maneja POST /api/contactos/webhooks/deliveries/{id}/retry
+func (h *ContactoHandler) RetryWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	delivery, err := h.service.RetryWebhookDelivery(mux.Vars(r)["id"])
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	utils.AcceptedResponse(w, delivery, "")
+}
+
+// writeWebhookError traduce los errores de webhooks a códigos HTTP
+func writeWebhookError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrWebhookNotFound), errors.Is(err, services.ErrDeliveryNotFound):
+		utils.NotFoundResponse(w, err.Error())
+	case errors.Is(err, services.ErrDeliveryNotFailed):
+		utils.ConflictResponse(w, err.Error())
+	default:
+		utils.InternalServerErrorResponse(w, err.Error())
+	}
+}
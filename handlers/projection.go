@@ -0,0 +1,139 @@
+// handlers/projection.go
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+
+	"contactos-api/exporters"
+	"contactos-api/models"
+	"contactos-api/services"
+)
+
+// contactoFields campos de contacto que se pueden pedir con fields=
+var contactoFields = exporters.Columns
+
+// rowDataFields campos de las filas inválidas que se pueden pedir con fields=
+var rowDataFields = []string{"row", "claveCliente", "nombre", "correo", "telefonoContacto", "hasErrors", "errorCount", "errors"}
+
+// fieldSet campos pedidos con fields=, en el orden indicado; nil = elementos completos
+type fieldSet []string
+
+// parseFields lee fields=nombre,telefonoContacto y valida cada nombre contra los permitidos
+func parseFields(query url.Values, allowed []string) (fieldSet, []models.ErrorResponse) {
+	spec := query.Get("fields")
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+
+	var fields fieldSet
+	var errores []models.ErrorResponse
+	seen := make(map[string]bool)
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		switch {
+		case !containsString(allowed, field):
+			errores = append(errores, models.ErrorResponse{
+				Campo:   "fields",
+				Mensaje: fmt.Sprintf("Campo '%s' desconocido. Use %s", field, strings.Join(allowed, ", ")),
+			})
+		case seen[field]:
+			errores = append(errores, models.ErrorResponse{
+				Campo:   "fields",
+				Mensaje: fmt.Sprintf("Campo '%s' repetido", field),
+			})
+		default:
+			seen[field] = true
+			fields = append(fields, field)
+		}
+	}
+	return fields, errores
+}
+
+// project recorta cada elemento de una lista de structs a los campos pedidos (por su nombre JSON);
+// sin campos retorna la lista tal cual
+func (fields fieldSet) project(items interface{}) interface{} {
+	if fields == nil {
+		return items
+	}
+
+	list := reflect.ValueOf(items)
+	projected := make([]projectedItem, list.Len())
+	for i := range projected {
+		item := reflect.Indirect(list.Index(i))
+		index := jsonFieldIndex(item.Type())
+		projected[i] = projectedItem{keys: fields, values: make([]interface{}, len(fields))}
+		for f, field := range fields {
+			projected[i].values[f] = item.Field(index[field]).Interface()
+		}
+	}
+	return projected
+}
+
+// page resultado paginado con Data recortada a los campos pedidos
+func (fields fieldSet) page(result *services.PaginatedResult) interface{} {
+	if fields == nil {
+		return result
+	}
+	return struct {
+		*services.PaginatedResult
+		Data interface{} `json:"data"`
+	}{result, fields.project(result.Data)}
+}
+
+// projectedItem objeto JSON con las llaves en el orden pedido
+type projectedItem struct {
+	keys   []string
+	values []interface{}
+}
+
+func (p projectedItem) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range p.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		value, err := json.Marshal(p.values[i])
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&buf, "%q:", key)
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// jsonFieldIndexes posición de cada campo por nombre JSON, por tipo
+var jsonFieldIndexes sync.Map // reflect.Type -> map[string]int
+
+func jsonFieldIndex(t reflect.Type) map[string]int {
+	if index, ok := jsonFieldIndexes.Load(t); ok {
+		return index.(map[string]int)
+	}
+
+	index := make(map[string]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			index[name] = i
+		}
+	}
+	jsonFieldIndexes.Store(t, index)
+	return index
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+// handlers/search_params.go
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"contactos-api/models"
+	"contactos-api/querylang"
+	"contactos-api/services"
+)
+
+// parseSearchOptions lee los criterios de búsqueda comunes a /search, /paginated y /export.
+// Acepta "q" (como /search) o "search" (como /paginated).
+func parseSearchOptions(query url.Values) (services.SearchOptions, []models.ErrorResponse) {
+	param, term := "q", query.Get("q")
+	if term == "" {
+		param, term = "search", query.Get("search")
+	}
+	opts := services.SearchOptions{Query: term, Mode: services.SearchModeSubstring}
+
+	var errores []models.ErrorResponse
+	if mode := query.Get("mode"); mode != "" {
+		switch mode {
+		case services.SearchModeSubstring, services.SearchModeFuzzy, services.SearchModeQuery, services.SearchModeRegex:
+			opts.Mode = mode
+		default:
+			errores = append(errores, models.ErrorResponse{
+				Campo:   "mode",
+				Mensaje: fmt.Sprintf("Modo de búsqueda '%s' inválido. Use substring, fuzzy, query o regex", mode),
+			})
+		}
+	}
+
+	// La consulta estructurada se valida aquí para responder con la posición del error
+	if opts.Mode == services.SearchModeQuery && term != "" {
+		if _, err := querylang.Parse(term); err != nil {
+			errores = append(errores, models.ErrorResponse{Campo: param, Mensaje: err.Error()})
+		}
+	}
+
+	// Patrón regex: límite de longitud y errores de compilación antes de recorrer los datos
+	if opts.Mode == services.SearchModeRegex && term != "" {
+		if _, err := services.CompileSearchRegex(term); err != nil {
+			errores = append(errores, models.ErrorResponse{Campo: param, Mensaje: err.Error()})
+		}
+	}
+
+	if field := query.Get("field"); field != "" && field != "all" {
+		if opts.Mode != services.SearchModeRegex {
+			errores = append(errores, models.ErrorResponse{
+				Campo:   "field",
+				Mensaje: "field solo aplica con mode=regex",
+			})
+		} else if f, ok := querylang.LookupField(field); ok {
+			opts.Field = f
+		} else {
+			errores = append(errores, models.ErrorResponse{
+				Campo:   "field",
+				Mensaje: fmt.Sprintf("Campo '%s' inválido. Use nombre, correo, telefono, clave o all", field),
+			})
+		}
+	}
+
+	if minScore := query.Get("minScore"); minScore != "" {
+		score, err := strconv.ParseFloat(minScore, 64)
+		if err != nil || score <= 0 || score > 1 {
+			errores = append(errores, models.ErrorResponse{
+				Campo:   "minScore",
+				Mensaje: "minScore debe ser un número mayor a 0 y hasta 1",
+			})
+		} else {
+			opts.MinScore = score
+		}
+	}
+
+	// Resaltado: activo por defecto en modo regex, opcional en substring y fuzzy
+	opts.Highlight = opts.Mode == services.SearchModeRegex
+	if highlight := query.Get("highlight"); highlight != "" {
+		value, err := strconv.ParseBool(highlight)
+		if err != nil {
+			errores = append(errores, models.ErrorResponse{Campo: "highlight", Mensaje: "highlight debe ser true o false"})
+		} else {
+			opts.Highlight = value
+		}
+	}
+
+	facets, err := services.ParseFacets(query.Get("facets"))
+	if err != nil {
+		errores = append(errores, models.ErrorResponse{Campo: "facets", Mensaje: err.Error()})
+	}
+	opts.Facets = facets
+
+	if sortParam := query.Get("sort"); sortParam != "" {
+		spec, err := services.ParseSortSpec(sortParam)
+		if err != nil {
+			errores = append(errores, models.ErrorResponse{Campo: "sort", Mensaje: err.Error()})
+		} else {
+			opts.Sort = spec
+		}
+	}
+
+	return opts, errores
+}
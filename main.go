@@ -17,6 +17,7 @@ import (
 	"contactos-api/repositories"
 	"contactos-api/routes"
 	"contactos-api/services"
+	"contactos-api/validators"
 
 	"github.com/rs/cors"
 	"github.com/tealeg/xlsx/v3"
@@ -44,6 +45,18 @@ func main() {
 	var memBefore runtime.MemStats
 	runtime.ReadMemStats(&memBefore)
 	
+	// ✅ REGLAS DE VALIDACIÓN (se aplican desde la carga inicial del Excel)
+	validatorConfig := validators.DefaultValidatorConfig()
+	validatorConfig.TelefonoDigitos = cfg.TelefonoDigitos
+	if len(cfg.CorreoDominios) > 0 {
+		validatorConfig.DominiosCorreo = cfg.CorreoDominios
+	}
+	validator, err := validators.NewContactoValidatorWithConfig(validatorConfig)
+	if err != nil {
+		fmt.Printf("⚠️ Configuración de validación inválida, usando valores por defecto: %v\n", err)
+		validator = validators.NewContactoValidator()
+	}
+	
 	// 🗂️ INICIALIZAR REPOSITORIO
 	fmt.Printf("📄 Cargando archivo Excel: %s\n", cfg.ExcelFile)
 	
@@ -57,7 +70,7 @@ func main() {
 	var contactoRepo repositories.ContactoRepositoryInterface
 	
 	fmt.Println("🚀 Usando repositorio optimizado...")
-	contactoRepo = repositories.NewSimpleOptimizedContactoRepository(cfg.ExcelFile)
+	contactoRepo = repositories.NewSimpleOptimizedContactoRepositoryWithValidator(cfg.ExcelFile, validator)
 	
 	// Mostrar estadísticas si está disponible
 	if optimizedRepo, ok := contactoRepo.(*repositories.SimpleOptimizedContactoRepository); ok {
@@ -69,6 +82,18 @@ func main() {
 	// 🔧 INICIALIZAR SERVICIO
 	contactoService := services.NewContactoService(contactoRepo)
 	
+	// Mismas reglas que la carga: API, plantilla, importaciones y estadísticas
+	if err := contactoService.ConfigureValidator(validator.Config()); err != nil {
+		fmt.Printf("⚠️ No se pudieron aplicar las reglas de validación: %v\n", err)
+	}
+	
+	// 🔔 WEBHOOKS (outbox persistente)
+	if err := contactoService.ConfigureWebhooks(cfg.WebhooksFile, cfg.WebhookMaxAttempts, cfg.WebhookSecretKey); err != nil {
+		fmt.Printf("⚠️ Webhooks deshabilitados: %v\n", err)
+	} else {
+		fmt.Printf("🔔 Webhooks: %s\n", cfg.WebhooksFile)
+	}
+	
 	// 🌐 CONFIGURAR RUTAS
 	router := routes.SetupRoutes(contactoService)
 	
@@ -0,0 +1,24 @@
+// models/event_model.go
+package models
+
+// Tipos de evento del stream de cambios
+const (
+	EventCreated           = "created"
+	EventUpdated           = "updated"
+	EventDeleted           = "deleted"
+	EventReloaded          = "reloaded"
+	EventValidationChanged = "validation-changed"
+	// EventResync indica que se perdieron eventos y el cliente debe recargar sus datos
+	EventResync = "resync"
+)
+
+// ChangeEvent cambio en los contactos publicado por el stream de eventos
+type ChangeEvent struct {
+	ID        uint64 `json:"id"`
+	Type      string `json:"type"`
+	Source    string `json:"source"` // "api", "import" o "reload"
+	Claves    []int  `json:"claves"`
+	Count     int    `json:"count"`               // Total de claves afectadas
+	Truncated bool   `json:"truncated,omitempty"` // Claves recortadas a maxEventClaves
+	Timestamp string `json:"timestamp"`
+}
@@ -2,6 +2,16 @@ package models
 
 import "time"
 
+// Códigos de error de fila; se asignan donde se detecta el error para no depender del mensaje
+const (
+	RowErrorVacio      = "vacio"
+	RowErrorDuplicado  = "duplicado"
+	RowErrorLongitud   = "longitud"
+	RowErrorNumerico   = "formato_numerico"
+	RowErrorCorreo     = "formato_correo"
+	RowErrorEstructura = "estructura"
+)
+
 // RowError representa un error específico en una fila del Excel
 type RowError struct {
 	Row     int      `json:"row"`
@@ -9,11 +19,13 @@ type RowError struct {
 	Field   string   `json:"field"`
 	Value   string   `json:"value"`
 	Error   string   `json:"error"`
+	Code    string   `json:"code,omitempty"` // Tipo de error (RowErrorVacio, RowErrorDuplicado, ...)
 	RowData *RowData `json:"rowData,omitempty"`
 }
 
 // RowData representa los datos completos de una fila (válida o inválida)
 type RowData struct {
+	Row              int    `json:"row,omitempty"` // Número de fila en el Excel (1 = encabezados)
 	ClaveCliente     string `json:"claveCliente"`
 	Nombre           string `json:"nombre"`
 	Correo           string `json:"correo"`
@@ -43,20 +55,62 @@ type ExcelValidationReport struct {
 	InvalidRowsData []RowData   `json:"invalidRowsData"`
 	LoadTimestamp   string      `json:"loadTimestamp"`
 	Summary         *ReportSummary `json:"summary,omitempty"`
+	Diff            *ValidationDiff `json:"diff,omitempty"` // Solo en la respuesta de recarga
+}
+
+// ValidationDiff representa los cambios entre la carga anterior y la actual
+type ValidationDiff struct {
+	PreviousLoadTimestamp string           `json:"previousLoadTimestamp"`
+	CurrentLoadTimestamp  string           `json:"currentLoadTimestamp"`
+	Counts                DiffCounts       `json:"counts"`
+	NewlyInvalidRows      []RowData        `json:"newlyInvalidRows"`
+	FixedRows             []RowData        `json:"fixedRows"` // Datos anteriores de filas que ahora son válidas
+	AddedContacts         []Contacto       `json:"addedContacts"`
+	RemovedContacts       []Contacto       `json:"removedContacts"`
+	ChangedContacts       []ContactoChange `json:"changedContacts"`
+}
+
+// DiffCounts resume la cantidad de cambios de cada tipo
+type DiffCounts struct {
+	NewlyInvalid int `json:"newlyInvalid"`
+	Fixed        int `json:"fixed"`
+	Added        int `json:"added"`
+	Removed      int `json:"removed"`
+	Changed      int `json:"changed"`
+}
+
+// ContactoChange representa un contacto modificado con el detalle por campo
+type ContactoChange struct {
+	ClaveCliente int           `json:"claveCliente"`
+	Before       Contacto      `json:"before"`
+	After        Contacto      `json:"after"`
+	Changes      []FieldChange `json:"changes"`
+}
+
+// FieldChange representa el cambio de valor de un campo
+type FieldChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
 }
 
 // ReportSummary proporciona un resumen de los tipos de errores más comunes
 type ReportSummary struct {
-	ErrorsByField map[string]int `json:"errorsByField"`
-	ErrorsByType  map[string]int `json:"errorsByType"`
-	MostCommonErrors []CommonError `json:"mostCommonErrors"`
+	TotalErrors         int            `json:"totalErrors"`
+	AffectedRows        int            `json:"affectedRows"`
+	AffectedRowsPercent float64        `json:"affectedRowsPercent"` // Porcentaje de filas con al menos un error
+	ErrorsByField       map[string]int `json:"errorsByField"`
+	ErrorsByType        map[string]int `json:"errorsByType"`
+	MostCommonErrors    []CommonError  `json:"mostCommonErrors"`
 }
 
 // CommonError representa un error común con su frecuencia
 type CommonError struct {
-	Message string `json:"message"`
-	Count   int    `json:"count"`
-	Field   string `json:"field"`
+	Message    string `json:"message"`
+	Count      int    `json:"count"`
+	Field      string `json:"field"`
+	Type       string `json:"type"`
+	SampleRows []int  `json:"sampleRows"` // Algunas filas de ejemplo donde aparece el error
 }
 
 // ValidationError representa errores de validación de entrada
@@ -72,7 +126,9 @@ type ContactoStats struct {
 	TotalErrores     int                    `json:"totalErrores"`
 	TotalInvalidos   int                    `json:"totalInvalidos"`
 	PorcentajeValidos float64               `json:"porcentajeValidos"`
+	TotalDominios    int                    `json:"totalDominios"`
 	TopDominios      []DominioStats         `json:"topDominios"`
+	AreasTelefonicas []AreaStats            `json:"areasTelefonicas"`
 	EstadisticasPorCampo map[string]FieldStats `json:"estadisticasPorCampo"`
 	Timestamp        string                 `json:"timestamp"`
 }
@@ -84,6 +140,13 @@ type DominioStats struct {
 	Porcentaje float64 `json:"porcentaje"`
 }
 
+// AreaStats representa la distribución de teléfonos por clave de área (LADA)
+type AreaStats struct {
+	Area       string  `json:"area"`
+	Count      int     `json:"count"`
+	Porcentaje float64 `json:"porcentaje"`
+}
+
 // FieldStats representa estadísticas de un campo específico
 type FieldStats struct {
 	ValoresUnicos int     `json:"valoresUnicos"`
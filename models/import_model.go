@@ -0,0 +1,50 @@
+// models/import_model.go
+package models
+
+// ImportResult representa el resultado de importar un archivo de contactos
+type ImportResult struct {
+	FileName  string                 `json:"fileName"`
+	Format    string                 `json:"format"`
+	Mode      string                 `json:"mode"`
+	Inserted  int                    `json:"inserted"`
+	Updated   int                    `json:"updated"`
+	Unchanged int                    `json:"unchanged"`
+	Deleted   int                    `json:"deleted"` // Solo en modo replace
+	Skipped   []int                  `json:"skipped"` // Claves existentes omitidas en modo insert
+	Report    *ExcelValidationReport `json:"report"`
+}
+
+// ImportPreview representa el plan de cambios de una importación sin aplicar (dry-run)
+type ImportPreview struct {
+	Token     string                 `json:"token"` // Permite aplicar este mismo plan más tarde
+	ExpiresAt string                 `json:"expiresAt"`
+	FileName  string                 `json:"fileName"`
+	Format    string                 `json:"format"`
+	Mode      string                 `json:"mode"`
+	Counts    ImportCounts           `json:"counts"`
+	Inserts   []Contacto             `json:"inserts"`
+	Updates   []ContactoChange       `json:"updates"`
+	Deletes   []Contacto             `json:"deletes"` // Solo en modo replace
+	Rejected  []RowData              `json:"rejected"`
+	Conflicts []ImportConflict       `json:"conflicts"`
+	Report    *ExcelValidationReport `json:"report"`
+}
+
+// ImportCounts resume la cantidad de filas de cada tipo en el plan
+type ImportCounts struct {
+	Inserts   int `json:"inserts"`
+	Updates   int `json:"updates"`
+	Unchanged int `json:"unchanged"`
+	Deletes   int `json:"deletes"`
+	Rejected  int `json:"rejected"`
+	Conflicts int `json:"conflicts"`
+}
+
+// ImportConflict representa una fila cuya clave ya existe con datos distintos
+type ImportConflict struct {
+	ClaveCliente int           `json:"claveCliente"`
+	Existing     Contacto      `json:"existing"`
+	Incoming     Contacto      `json:"incoming"`
+	Changes      []FieldChange `json:"changes"`
+	Resolution   string        `json:"resolution"` // "sobrescribir" (upsert/replace) u "omitir" (insert)
+}
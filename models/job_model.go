@@ -0,0 +1,36 @@
+// models/job_model.go
+package models
+
+// Estados de un trabajo en segundo plano
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+	JobStatusCancelled = "cancelled"
+)
+
+// Job representa un trabajo de importación o recarga en segundo plano
+type Job struct {
+	ID            string  `json:"id"`
+	Type          string  `json:"type"` // "import" o "reload"
+	Status        string  `json:"status"`
+	Phase         string  `json:"phase,omitempty"` // abriendo, validando o aplicando mientras corre
+	FileName      string  `json:"fileName,omitempty"`
+	Mode          string  `json:"mode,omitempty"`
+	TotalRows     int     `json:"totalRows"`
+	RowsProcessed int     `json:"rowsProcessed"`
+	ValidRows     int     `json:"validRows"`
+	InvalidRows   int     `json:"invalidRows"`
+	Progress      float64 `json:"progress"`             // Porcentaje de filas procesadas
+	ETASeconds    float64 `json:"etaSeconds,omitempty"` // Tiempo restante estimado
+	Error         string  `json:"error,omitempty"`
+	CreatedAt     string  `json:"createdAt"`
+	StartedAt     string  `json:"startedAt,omitempty"`
+	FinishedAt    string  `json:"finishedAt,omitempty"`
+}
+
+// IsFinished indica si el trabajo ya no está en ejecución
+func (j *Job) IsFinished() bool {
+	return j.Status == JobStatusCompleted || j.Status == JobStatusFailed || j.Status == JobStatusCancelled
+}
@@ -0,0 +1,55 @@
+// models/rule_test_model.go
+package models
+
+// Orígenes de una fila evaluada por el probador de reglas
+const (
+	RuleSampleContacto = "contacto"     // Contacto cargado
+	RuleSampleInvalida = "filaInvalida" // Fila rechazada en la carga
+)
+
+// RuleTestRequest patrones candidatos a probar contra los datos cargados.
+// Se indica un solo campo (Field + Pattern) o un conjunto de reglas (Rules: campo -> patrón).
+// Cada patrón debe coincidir con el valor completo (se evalúa como ^(?:patrón)$).
+type RuleTestRequest struct {
+	Field   string            `json:"field,omitempty"`
+	Pattern string            `json:"pattern,omitempty"`
+	Rules   map[string]string `json:"rules,omitempty"`
+	Samples int               `json:"samples,omitempty"` // Ejemplos por grupo (default 10, máximo 100)
+}
+
+// RuleTestResult impacto de los patrones candidatos frente a las reglas activas.
+// Una fila "pasa" si todos los campos probados son válidos; los demás campos no se consideran.
+type RuleTestResult struct {
+	Rules              map[string]string `json:"rules"`
+	RowsEvaluated      int               `json:"rowsEvaluated"`
+	ContactosEvaluados int               `json:"contactosEvaluados"`
+	InvalidosEvaluados int               `json:"invalidosEvaluados"`
+	CurrentlyFailing   int               `json:"currentlyFailing"`
+	CandidateFailing   int               `json:"candidateFailing"`
+	NewlyFailing       int               `json:"newlyFailing"`
+	NewlyPassing       int               `json:"newlyPassing"`
+	Fields             []RuleFieldImpact `json:"fields"`
+	NewlyFailingRows   []RuleTestSample  `json:"newlyFailingSamples"`
+	NewlyPassingRows   []RuleTestSample  `json:"newlyPassingSamples"`
+}
+
+// RuleFieldImpact impacto de la regla candidata de un campo
+type RuleFieldImpact struct {
+	Field             string `json:"field"`
+	Pattern           string `json:"pattern"`
+	CurrentFailures   int    `json:"currentFailures"`
+	CandidateFailures int    `json:"candidateFailures"`
+	NewlyFailing      int    `json:"newlyFailing"`
+	NewlyPassing      int    `json:"newlyPassing"`
+}
+
+// RuleTestSample fila cuyo resultado cambia con las reglas candidatas
+type RuleTestSample struct {
+	Source           string   `json:"source"`        // RuleSampleContacto o RuleSampleInvalida
+	Row              int      `json:"row,omitempty"` // Fila del Excel (solo filas inválidas)
+	ClaveCliente     string   `json:"claveCliente"`
+	Nombre           string   `json:"nombre"`
+	Correo           string   `json:"correo"`
+	TelefonoContacto string   `json:"telefonoContacto"`
+	Changed          []string `json:"changedFields"` // Campos cuyo resultado cambia
+}
@@ -0,0 +1,53 @@
+// models/transform_model.go
+package models
+
+// TransformRequest buscar y reemplazar con expresión regular sobre un campo de los contactos
+type TransformRequest struct {
+	Field     string           `json:"field"`               // claveCliente, nombre, correo o telefonoContacto
+	Find      string           `json:"find"`                // Patrón RE2
+	Replace   string           `json:"replace"`             // Reemplazo; acepta $1, ${nombre}
+	Case      string           `json:"case,omitempty"`      // "lower" o "upper" sobre el texto reemplazado
+	Filter    *TransformFilter `json:"filter,omitempty"`    // Subconjunto de contactos (vacío = todos)
+	OnInvalid string           `json:"onInvalid,omitempty"` // "abort" (default) u "omit"
+	Samples   int              `json:"samples,omitempty"`   // Ejemplos en la respuesta (default 20, máximo 200)
+}
+
+// TransformFilter criterios de búsqueda que delimitan los contactos a transformar
+type TransformFilter struct {
+	Q        string  `json:"q"`
+	Mode     string  `json:"mode,omitempty"`
+	MinScore float64 `json:"minScore,omitempty"`
+	Field    string  `json:"field,omitempty"` // Campo del patrón en mode=regex
+}
+
+// TransformChange valor de un contacto antes y después del reemplazo
+type TransformChange struct {
+	ClaveCliente int    `json:"claveCliente"`
+	Before       string `json:"before"`
+	After        string `json:"after"`
+	Error        string `json:"error,omitempty"` // Motivo si el resultado no pasa la validación
+}
+
+// TransformResult vista previa o resultado de una transformación
+type TransformResult struct {
+	Field         string            `json:"field"`
+	Find          string            `json:"find"`
+	Replace       string            `json:"replace"`
+	Case          string            `json:"case,omitempty"`
+	DryRun        bool              `json:"dryRun"`
+	Scanned       int               `json:"scanned"`  // Contactos en el subconjunto
+	Matched       int               `json:"matched"`  // Contactos donde el patrón coincide
+	Changed       int               `json:"changed"`  // Contactos cuyo valor cambia y es válido
+	Invalid       int               `json:"invalid"`  // Contactos cuyo valor resultante no es válido
+	Applied       int               `json:"applied"`  // Contactos guardados (0 en vista previa)
+	Changes       []TransformChange `json:"changes"`  // Ejemplos de cambios válidos
+	Rejected      []TransformChange `json:"rejected"` // Ejemplos de resultados inválidos
+	UndoID        string            `json:"undoId,omitempty"`
+	UndoExpiresAt string            `json:"undoExpiresAt,omitempty"`
+}
+
+// TransformUndoResult resultado de deshacer una transformación
+type TransformUndoResult struct {
+	UndoID   string `json:"undoId"`
+	Restored int    `json:"restored"`
+}
@@ -0,0 +1,57 @@
+// models/webhook_model.go
+package models
+
+import "encoding/json"
+
+// Estados de una entrega de webhook
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusDelivered = "delivered"
+	DeliveryStatusFailed    = "failed"
+)
+
+// WebhookSubscription suscripción de un sistema externo a los cambios de contactos
+type WebhookSubscription struct {
+	ID        string   `json:"id"`
+	URL       string   `json:"url"`
+	Events    []string `json:"events"`           // Tipos de evento; vacío = todos
+	Secret    string   `json:"secret,omitempty"` // Solo se muestra al crearla
+	Active    bool     `json:"active"`
+	CreatedAt string   `json:"createdAt"`
+}
+
+// WebhookRequest datos para crear una suscripción
+type WebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"` // Opcional; se genera si viene vacío
+}
+
+// WebhookPayload cuerpo JSON enviado al suscriptor
+type WebhookPayload struct {
+	DeliveryID string     `json:"deliveryId"`
+	EventID    uint64     `json:"eventId"`
+	Event      string     `json:"event"`
+	Source     string     `json:"source"`
+	Timestamp  string     `json:"timestamp"`
+	Claves     []int      `json:"claves"`
+	Count      int        `json:"count"`
+	Truncated  bool       `json:"truncated,omitempty"`
+	Contactos  []Contacto `json:"contactos,omitempty"` // Estado actual en created/updated
+}
+
+// WebhookDelivery entrega pendiente, fallida o completada del outbox
+type WebhookDelivery struct {
+	ID             string          `json:"id"`
+	SubscriptionID string          `json:"subscriptionId"`
+	URL            string          `json:"url"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  string          `json:"nextAttemptAt,omitempty"`
+	LastStatusCode int             `json:"lastStatusCode,omitempty"`
+	LastError      string          `json:"lastError,omitempty"`
+	CreatedAt      string          `json:"createdAt"`
+	DeliveredAt    string          `json:"deliveredAt,omitempty"`
+}
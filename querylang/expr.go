@@ -0,0 +1,207 @@
+// querylang/expr.go
+package querylang
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"contactos-api/models"
+	"contactos-api/repositories"
+)
+
+// Field campo al que aplica un término
+type Field int
+
+const (
+	FieldAny Field = iota // Sin selector: nombre, correo, teléfono o clave
+	FieldNombre
+	FieldCorreo
+	FieldTelefono
+	FieldClave
+)
+
+// fieldNames selectores aceptados (sin distinguir mayúsculas)
+var fieldNames = map[string]Field{
+	"nombre":           FieldNombre,
+	"correo":           FieldCorreo,
+	"email":            FieldCorreo,
+	"telefono":         FieldTelefono,
+	"telefonocontacto": FieldTelefono,
+	"clave":            FieldClave,
+	"clavecliente":     FieldClave,
+}
+
+// LookupField busca un selector de campo por nombre (sin distinguir mayúsculas)
+func LookupField(name string) (Field, bool) {
+	field, ok := fieldNames[strings.ToLower(name)]
+	return field, ok
+}
+
+// String nombre JSON del campo ("" para FieldAny)
+func (f Field) String() string {
+	switch f {
+	case FieldNombre:
+		return "nombre"
+	case FieldCorreo:
+		return "correo"
+	case FieldTelefono:
+		return "telefonoContacto"
+	case FieldClave:
+		return "claveCliente"
+	default:
+		return ""
+	}
+}
+
+// Expr consulta ya analizada; Match evalúa un contacto con sus claves normalizadas
+type Expr interface {
+	Match(contacto models.Contacto, keys repositories.SearchKeys) bool
+	// required agrega las subcadenas que todo contacto que cumple contiene en algún campo
+	required(dst []string) []string
+}
+
+// RequiredTerms subcadenas normalizadas presentes en todo resultado; permiten usar el
+// índice invertido para descartar candidatos antes de evaluar la consulta completa
+func RequiredTerms(expr Expr) []string {
+	return expr.required(nil)
+}
+
+type andExpr struct{ left, right Expr }
+
+func (e *andExpr) Match(contacto models.Contacto, keys repositories.SearchKeys) bool {
+	return e.left.Match(contacto, keys) && e.right.Match(contacto, keys)
+}
+
+func (e *andExpr) required(dst []string) []string {
+	return e.right.required(e.left.required(dst))
+}
+
+type orExpr struct{ left, right Expr }
+
+func (e *orExpr) Match(contacto models.Contacto, keys repositories.SearchKeys) bool {
+	return e.left.Match(contacto, keys) || e.right.Match(contacto, keys)
+}
+
+// Ninguna rama es obligatoria por sí sola
+func (e *orExpr) required(dst []string) []string { return dst }
+
+type notExpr struct{ operand Expr }
+
+func (e *notExpr) Match(contacto models.Contacto, keys repositories.SearchKeys) bool {
+	return !e.operand.Match(contacto, keys)
+}
+
+func (e *notExpr) required(dst []string) []string { return dst }
+
+type matchKind int
+
+const (
+	matchSubstring matchKind = iota // Valor contenido en el campo
+	matchGlob                       // Campo completo contra un patrón con * y ?
+	matchExact                      // Clave igual al número
+	matchRange                      // Clave dentro de [min, max]
+)
+
+// termExpr término simple: campo + valor normalizado
+type termExpr struct {
+	field    Field
+	kind     matchKind
+	value    string
+	min, max int
+}
+
+func (e *termExpr) Match(contacto models.Contacto, keys repositories.SearchKeys) bool {
+	switch e.kind {
+	case matchExact:
+		return contacto.ClaveCliente == e.min
+	case matchRange:
+		return contacto.ClaveCliente >= e.min && contacto.ClaveCliente <= e.max
+	}
+
+	if e.field != FieldAny {
+		return e.matchText(fieldKey(keys, e.field))
+	}
+	return e.matchText(keys.Nombre) || e.matchText(keys.Correo) ||
+		e.matchText(keys.Telefono) || e.matchText(keys.Clave)
+}
+
+func (e *termExpr) matchText(text string) bool {
+	if e.kind == matchGlob {
+		return globMatch(e.value, text)
+	}
+	return strings.Contains(text, e.value)
+}
+
+func (e *termExpr) required(dst []string) []string {
+	switch e.kind {
+	case matchSubstring, matchExact:
+		return append(dst, e.value)
+	case matchGlob:
+		// Los fragmentos literales del patrón aparecen en el campo
+		for _, literal := range strings.FieldsFunc(e.value, isWildcard) {
+			dst = append(dst, literal)
+		}
+	}
+	return dst
+}
+
+func fieldKey(keys repositories.SearchKeys, field Field) string {
+	switch field {
+	case FieldNombre:
+		return keys.Nombre
+	case FieldCorreo:
+		return keys.Correo
+	case FieldTelefono:
+		return keys.Telefono
+	default:
+		return keys.Clave
+	}
+}
+
+func isWildcard(r rune) bool {
+	return r == '*' || r == '?'
+}
+
+// globMatch compara el texto completo con un patrón: * es cualquier secuencia y ? un carácter
+func globMatch(pattern, text string) bool {
+	px, tx := 0, 0
+	// Posición a la que volver si falla la comparación después del último *
+	nextPx, nextTx := 0, 0
+	for px < len(pattern) || tx < len(text) {
+		if px < len(pattern) {
+			switch c := pattern[px]; c {
+			case '*':
+				nextPx, nextTx = px, tx+runeLen(text, tx)
+				px++
+				continue
+			case '?':
+				if tx < len(text) {
+					px++
+					tx += runeLen(text, tx)
+					continue
+				}
+			default:
+				if tx < len(text) && text[tx] == c {
+					px++
+					tx++
+					continue
+				}
+			}
+		}
+		if 0 < nextTx && nextTx <= len(text) {
+			px, tx = nextPx, nextTx
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// runeLen bytes del carácter en la posición i (1 al final del texto)
+func runeLen(text string, i int) int {
+	if i >= len(text) {
+		return 1
+	}
+	_, size := utf8.DecodeRuneInString(text[i:])
+	return size
+}
@@ -0,0 +1,172 @@
+// querylang/lexer.go
+package querylang
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenTerm
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenLParen
+	tokenRParen
+)
+
+// token elemento léxico; pos es el desplazamiento en bytes dentro de la consulta
+type token struct {
+	kind     tokenKind
+	pos      int
+	field    string // Selector antes de ':' (vacío si no hay)
+	fieldPos int
+	value    string
+	valuePos int
+	quoted   bool
+}
+
+// SyntaxError error de sintaxis; Position es la columna (en caracteres, desde 1) donde falla
+type SyntaxError struct {
+	Position int
+	Message  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("error de sintaxis en la posición %d: %s", e.Position, e.Message)
+}
+
+// lexer separa la consulta en términos, operadores y paréntesis
+type lexer struct {
+	src string
+	pos int
+}
+
+// column columna (en caracteres, desde 1) de un desplazamiento en bytes
+func (l *lexer) column(offset int) int {
+	return utf8.RuneCountInString(l.src[:offset]) + 1
+}
+
+// errorAt crea un error de sintaxis en un desplazamiento en bytes
+func (l *lexer) errorAt(offset int, format string, args ...interface{}) *SyntaxError {
+	return &SyntaxError{Position: l.column(offset), Message: fmt.Sprintf(format, args...)}
+}
+
+func (l *lexer) tokens() ([]token, error) {
+	var tokens []token
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, tok)
+		if tok.kind == tokenEOF {
+			return tokens, nil
+		}
+	}
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.src) {
+		r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		l.pos += size
+	}
+	if l.pos == len(l.src) {
+		return token{kind: tokenEOF, pos: l.pos}, nil
+	}
+
+	start := l.pos
+	switch l.src[l.pos] {
+	case '(':
+		l.pos++
+		return token{kind: tokenLParen, pos: start}, nil
+	case ')':
+		l.pos++
+		return token{kind: tokenRParen, pos: start}, nil
+	case '"':
+		value, err := l.quoted()
+		if err != nil {
+			return token{}, err
+		}
+		return token{kind: tokenTerm, pos: start, value: value, valuePos: start, quoted: true}, nil
+	}
+
+	word := l.word()
+	switch word {
+	case "AND":
+		return token{kind: tokenAnd, pos: start}, nil
+	case "OR":
+		return token{kind: tokenOr, pos: start}, nil
+	case "NOT":
+		return token{kind: tokenNot, pos: start}, nil
+	}
+
+	tok := token{kind: tokenTerm, pos: start, value: word, valuePos: start}
+	colon := strings.IndexByte(word, ':')
+	if colon < 0 {
+		return tok, nil
+	}
+
+	tok.field, tok.fieldPos = word[:colon], start
+	tok.value, tok.valuePos = word[colon+1:], start+colon+1
+	if tok.field == "" {
+		return token{}, l.errorAt(start, "falta el nombre del campo antes de ':'")
+	}
+	if tok.value == "" && l.pos < len(l.src) && l.src[l.pos] == '"' {
+		// Selector con frase: nombre:"ana maria"
+		tok.valuePos = l.pos
+		value, err := l.quoted()
+		if err != nil {
+			return token{}, err
+		}
+		tok.value, tok.quoted = value, true
+	}
+	if tok.value == "" && !tok.quoted {
+		return token{}, l.errorAt(tok.valuePos, "falta el valor del campo '%s'", tok.field)
+	}
+	return tok, nil
+}
+
+// word lee una palabra hasta un espacio, un paréntesis o una comilla
+func (l *lexer) word() string {
+	start := l.pos
+	for l.pos < len(l.src) {
+		r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+		if unicode.IsSpace(r) || r == '(' || r == ')' || r == '"' {
+			break
+		}
+		l.pos += size
+	}
+	return l.src[start:l.pos]
+}
+
+// quoted lee una frase entre comillas; \" y \\ escapan dentro de la frase
+func (l *lexer) quoted() (string, error) {
+	start := l.pos
+	l.pos++ // Comilla de apertura
+
+	var value strings.Builder
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '"':
+			l.pos++
+			return value.String(), nil
+		case c == '\\' && l.pos+1 < len(l.src) && (l.src[l.pos+1] == '"' || l.src[l.pos+1] == '\\'):
+			value.WriteByte(l.src[l.pos+1])
+			l.pos += 2
+		default:
+			value.WriteByte(c)
+			l.pos++
+		}
+	}
+	return "", l.errorAt(start, "comilla sin cerrar")
+}
@@ -0,0 +1,244 @@
+// querylang/parser.go
+//
+// Lenguaje de consulta para filtrar contactos, por ejemplo:
+//
+//	correo:*@gmail.com AND (nombre:"ana maria" OR telefono:55*) NOT clave:1000..2000
+//
+// Sintaxis:
+//
+//	campo:valor        nombre, correo (email), telefono (telefonoContacto), clave (claveCliente);
+//	                   sin campo busca en todos
+//	valor              subcadena sin acentos ni mayúsculas; con * o ? el patrón cubre el campo completo
+//	"frase"            subcadena literal con espacios (los comodines no aplican)
+//	clave:N            clave exacta; clave:N..M, clave:N.. y clave:..M son rangos inclusivos
+//	AND, OR, NOT, ( )  en mayúsculas; dos términos seguidos equivalen a AND.
+//	                   NOT tiene mayor precedencia que AND, y AND mayor que OR.
+package querylang
+
+import (
+	"strconv"
+	"strings"
+
+	"contactos-api/utils"
+)
+
+const (
+	// MaxQueryLength longitud máxima de una consulta en bytes
+	MaxQueryLength = 1000
+	// maxDepth anidamiento máximo de paréntesis y NOT
+	maxDepth = 32
+)
+
+// Parse analiza una consulta; los errores son *SyntaxError con la posición que falla
+func Parse(query string) (Expr, error) {
+	lex := &lexer{src: query}
+	if len(query) > MaxQueryLength {
+		return nil, lex.errorAt(MaxQueryLength, "la consulta supera los %d caracteres", MaxQueryLength)
+	}
+
+	tokens, err := lex.tokens()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{lex: lex, tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		if tok.kind == tokenRParen {
+			return nil, lex.errorAt(tok.pos, "')' sin paréntesis de apertura")
+		}
+		return nil, lex.errorAt(tok.pos, "término inesperado")
+	}
+	return expr, nil
+}
+
+// parser analizador descendente recursivo sobre los tokens
+type parser struct {
+	lex    *lexer
+	tokens []token
+	pos    int
+	depth  int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) advance() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// parseOr or := and (OR and)*
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOr {
+		p.advance()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orExpr{left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseAnd and := unary ([AND] unary)*
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch p.peek().kind {
+		case tokenAnd:
+			p.advance()
+		case tokenTerm, tokenNot, tokenLParen:
+			// AND implícito
+		default:
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &andExpr{left: left, right: right}
+	}
+}
+
+// parseUnary unary := NOT unary | primary
+func (p *parser) parseUnary() (Expr, error) {
+	if p.peek().kind != tokenNot {
+		return p.parsePrimary()
+	}
+
+	tok := p.advance()
+	if p.depth++; p.depth > maxDepth {
+		return nil, p.lex.errorAt(tok.pos, "demasiados niveles de anidamiento (máximo %d)", maxDepth)
+	}
+	operand, err := p.parseUnary()
+	p.depth--
+	if err != nil {
+		return nil, err
+	}
+	return &notExpr{operand: operand}, nil
+}
+
+// parsePrimary primary := '(' or ')' | término
+func (p *parser) parsePrimary() (Expr, error) {
+	tok := p.advance()
+	switch tok.kind {
+	case tokenTerm:
+		return p.term(tok)
+	case tokenLParen:
+		if p.depth++; p.depth > maxDepth {
+			return nil, p.lex.errorAt(tok.pos, "demasiados niveles de anidamiento (máximo %d)", maxDepth)
+		}
+		expr, err := p.parseOr()
+		p.depth--
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.advance(); closing.kind != tokenRParen {
+			return nil, p.lex.errorAt(closing.pos, "se esperaba ')' para cerrar el paréntesis de la posición %d",
+				p.lex.column(tok.pos))
+		}
+		return expr, nil
+	case tokenRParen:
+		return nil, p.lex.errorAt(tok.pos, "se esperaba un término antes de ')'")
+	case tokenAnd, tokenOr:
+		return nil, p.lex.errorAt(tok.pos, "se esperaba un término antes de %s", operatorName(tok.kind))
+	default:
+		return nil, p.lex.errorAt(tok.pos, "se esperaba un término al final de la consulta")
+	}
+}
+
+func operatorName(kind tokenKind) string {
+	if kind == tokenAnd {
+		return "AND"
+	}
+	return "OR"
+}
+
+// term convierte un token en su condición: rango, clave exacta, patrón o subcadena
+func (p *parser) term(tok token) (Expr, error) {
+	field := FieldAny
+	if tok.field != "" {
+		f, ok := LookupField(tok.field)
+		if !ok {
+			return nil, p.lex.errorAt(tok.fieldPos, "campo '%s' desconocido; use nombre, correo, telefono o clave", tok.field)
+		}
+		field = f
+	}
+
+	if tok.quoted {
+		value := utils.NormalizeText(tok.value)
+		if value == "" {
+			return nil, p.lex.errorAt(tok.valuePos, "frase vacía")
+		}
+		return &termExpr{field: field, kind: matchSubstring, value: value}, nil
+	}
+
+	if strings.Contains(tok.value, "..") {
+		if field != FieldAny && field != FieldClave {
+			return nil, p.lex.errorAt(tok.valuePos, "los rangos solo aplican a clave")
+		}
+		return p.rangeTerm(tok)
+	}
+
+	if field == FieldClave && !strings.ContainsAny(tok.value, "*?") {
+		n, err := strconv.Atoi(tok.value)
+		if err != nil || n <= 0 {
+			return nil, p.lex.errorAt(tok.valuePos, "clave '%s' inválida: se esperaba un número entero positivo", tok.value)
+		}
+		return &termExpr{field: field, kind: matchExact, value: strconv.Itoa(n), min: n}, nil
+	}
+
+	value := utils.NormalizeText(tok.value)
+	if strings.ContainsAny(value, "*?") {
+		return &termExpr{field: field, kind: matchGlob, value: value}, nil
+	}
+	return &termExpr{field: field, kind: matchSubstring, value: value}, nil
+}
+
+// rangeTerm rango inclusivo de claves: N..M, N.. o ..M
+func (p *parser) rangeTerm(tok token) (Expr, error) {
+	sep := strings.Index(tok.value, "..")
+	from, to := tok.value[:sep], tok.value[sep+2:]
+	if from == "" && to == "" {
+		return nil, p.lex.errorAt(tok.valuePos, "rango sin límites: use N..M, N.. o ..M")
+	}
+
+	bound := func(text string, offset, empty int) (int, error) {
+		if text == "" {
+			return empty, nil
+		}
+		n, err := strconv.Atoi(text)
+		if err != nil || n < 0 {
+			return 0, p.lex.errorAt(tok.valuePos+offset, "límite de rango '%s' inválido: se esperaba un número entero", text)
+		}
+		return n, nil
+	}
+
+	minimo, err := bound(from, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	maximo, err := bound(to, sep+2, int(^uint(0)>>1))
+	if err != nil {
+		return nil, err
+	}
+	if minimo > maximo {
+		return nil, p.lex.errorAt(tok.valuePos, "rango vacío: %d es mayor que %d", minimo, maximo)
+	}
+	return &termExpr{field: FieldClave, kind: matchRange, min: minimo, max: maximo}, nil
+}
@@ -2,6 +2,7 @@
 package repositories
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
@@ -9,6 +10,8 @@ import (
 	"time"
     "regexp"
 	"contactos-api/models"
+	"contactos-api/utils"
+	"contactos-api/validators"
 
 	"github.com/tealeg/xlsx/v3"
 )
@@ -26,6 +29,52 @@ type ContactoRepositoryInterface interface {
 	ReloadExcel() ([]models.RowError, []models.RowData, error)
 }
 
+// ProgressReloader recarga con cancelación y reporte de avance
+type ProgressReloader interface {
+	ReloadExcelContext(ctx context.Context, progress ProgressFunc) ([]models.RowError, []models.RowData, error)
+}
+
+// StagedReloader recarga en dos pasos: ParseExcelContext procesa el archivo sin tomar locks del
+// repositorio y ApplyReload cambia los datos por el resultado, sin volver a guardar el archivo
+type StagedReloader interface {
+	ParseExcelContext(ctx context.Context, progress ProgressFunc) (*ParseResult, error)
+	ApplyReload(result *ParseResult)
+}
+
+// ValidatingLoader repositorios que validan las filas del Excel con reglas configurables; las
+// nuevas reglas se aplican desde la siguiente carga
+type ValidatingLoader interface {
+	SetValidator(validator *validators.ContactoValidator)
+}
+
+// TextSearcher búsqueda de texto libre sobre claves normalizadas (sin acentos ni mayúsculas)
+// en nombre, correo, teléfono y clave; retorna los contactos en su orden original
+type TextSearcher interface {
+	SearchText(term string) ([]models.Contacto, error)
+	// FilterText retorna los contactos que cumplen match; required son subcadenas normalizadas
+	// que todo resultado contiene en algún campo y sirven para descartar candidatos
+	FilterText(required []string, match func(models.Contacto, SearchKeys) bool) ([]models.Contacto, error)
+}
+
+// SourceRowTracker repositorios que recuerdan la fila del archivo de la que se cargó cada contacto
+type SourceRowTracker interface {
+	// SourceRows fila de origen por ClaveCliente; los contactos agregados después no aparecen
+	SourceRows() map[int]int
+}
+
+// SourceTracker repositorios que recuerdan de qué archivo (y hoja) se cargó o importó cada contacto
+type SourceTracker interface {
+	// Sources origen por ClaveCliente (ver SourceLabel); los contactos dados de alta por la API no aparecen
+	Sources() map[int]string
+}
+
+// BulkContactoRepository operaciones masivas disponibles en repositorios que las soportan
+type BulkContactoRepository interface {
+	ReplaceAll(result *ParseResult) error
+	// UpsertMany inserta o actualiza los contactos; source es el origen que se les registra
+	UpsertMany(contactos []models.Contacto, source string) error
+}
+
 // ContactoRepository implementa el acceso a datos para contactos
 type ContactoRepository struct {
 	excelFile        string
@@ -305,18 +354,18 @@ func (r *ContactoRepository) Search(criteria *models.ContactoDTO) ([]models.Cont
 			}
 		}
 		
-		// Filtrar por nombre (case insensitive, partial match)
+		// Filtrar por nombre (sin acentos ni mayúsculas, partial match)
 		if criteria.Nombre != "" && !strings.Contains(
-			strings.ToLower(contacto.Nombre), 
-			strings.ToLower(criteria.Nombre),
+			utils.NormalizeText(contacto.Nombre), 
+			utils.NormalizeText(criteria.Nombre),
 		) {
 			match = false
 		}
 		
-		// Filtrar por correo (case insensitive, partial match)
+		// Filtrar por correo (sin acentos ni mayúsculas, partial match)
 		if criteria.Correo != "" && !strings.Contains(
-			strings.ToLower(contacto.Correo), 
-			strings.ToLower(criteria.Correo),
+			utils.NormalizeText(contacto.Correo), 
+			utils.NormalizeText(criteria.Correo),
 		) {
 			match = false
 		}
@@ -443,6 +492,7 @@ func (r *ContactoRepository) loadFromExcel() ([]models.RowError, []models.RowDat
 			if hasContent {
 				// Crear RowData para fila incompleta
 				rowData := models.RowData{
+					Row:        currentRow,
 					HasErrors:  true,
 					ErrorCount: 1,
 				}
@@ -461,6 +511,7 @@ func (r *ContactoRepository) loadFromExcel() ([]models.RowError, []models.RowDat
 					Field:   "estructura",
 					Value:   "",
 					Error:   "La fila debe contener exactamente 4 columnas: ClaveCliente, Nombre, Correo, TelefonoContacto",
+					Code:   models.RowErrorEstructura,
 					RowData: &rowData,
 				})
 			}
@@ -486,6 +537,7 @@ func (r *ContactoRepository) loadFromExcel() ([]models.RowError, []models.RowDat
 
 		// Crear RowData
 		rowData := models.RowData{
+			Row:              currentRow,
 			ClaveCliente:     claveStr,
 			Nombre:           nombre,
 			Correo:           correo,
@@ -505,6 +557,7 @@ func (r *ContactoRepository) loadFromExcel() ([]models.RowError, []models.RowDat
 				Field:   "claveCliente",
 				Value:   claveStr,
 				Error:   "La clave cliente no puede estar vacía",
+				Code:   models.RowErrorVacio,
 				RowData: &rowData,
 			})
 		}
@@ -517,6 +570,7 @@ func (r *ContactoRepository) loadFromExcel() ([]models.RowError, []models.RowDat
 				Field:   "nombre",
 				Value:   nombre,
 				Error:   "El nombre no puede estar vacío",
+				Code:   models.RowErrorVacio,
 				RowData: &rowData,
 			})
 		}
@@ -529,6 +583,7 @@ func (r *ContactoRepository) loadFromExcel() ([]models.RowError, []models.RowDat
 				Field:   "correo",
 				Value:   correo,
 				Error:   "El correo no puede estar vacío",
+				Code:   models.RowErrorVacio,
 				RowData: &rowData,
 			})
 		}
@@ -541,6 +596,7 @@ func (r *ContactoRepository) loadFromExcel() ([]models.RowError, []models.RowDat
 				Field:   "telefonoContacto",
 				Value:   telefono,
 				Error:   "El teléfono no puede estar vacío",
+				Code:   models.RowErrorVacio,
 				RowData: &rowData,
 			})
 		}
@@ -557,6 +613,7 @@ func (r *ContactoRepository) loadFromExcel() ([]models.RowError, []models.RowDat
 					Field:   "claveCliente",
 					Value:   claveStr,
 					Error:   "La clave cliente debe ser un número entero válido",
+					Code:   models.RowErrorNumerico,
 					RowData: &rowData,
 				})
 			} else if clave <= 0 {
@@ -567,6 +624,7 @@ func (r *ContactoRepository) loadFromExcel() ([]models.RowError, []models.RowDat
 					Field:   "claveCliente",
 					Value:   claveStr,
 					Error:   "La clave cliente debe ser un número mayor a 0",
+					Code:   models.RowErrorNumerico,
 					RowData: &rowData,
 				})
 			} else {
@@ -580,6 +638,7 @@ func (r *ContactoRepository) loadFromExcel() ([]models.RowError, []models.RowDat
 							Field:   "claveCliente",
 							Value:   claveStr,
 							Error:   fmt.Sprintf("La clave cliente %d ya existe en el archivo", clave),
+							Code:   models.RowErrorDuplicado,
 							RowData: &rowData,
 						})
 						break
@@ -598,6 +657,7 @@ func (r *ContactoRepository) loadFromExcel() ([]models.RowError, []models.RowDat
 					Field:   "telefonoContacto",
 					Value:   telefono,
 					Error:   "El teléfono debe tener exactamente 10 dígitos",
+					Code:   models.RowErrorLongitud,
 					RowData: &rowData,
 				})
 			}
@@ -612,6 +672,7 @@ func (r *ContactoRepository) loadFromExcel() ([]models.RowError, []models.RowDat
 						Field:   "telefonoContacto",
 						Value:   telefono,
 						Error:   "El teléfono debe contener solo números",
+						Code:   models.RowErrorNumerico,
 						RowData: &rowData,
 					})
 					break
@@ -628,6 +689,7 @@ func (r *ContactoRepository) loadFromExcel() ([]models.RowError, []models.RowDat
 				Field:   "correo",
 				Value:   correo,
 				Error:   "El correo debe contener @",
+				Code:   models.RowErrorCorreo,
 				RowData: &rowData,
 			})
 		}
@@ -640,6 +702,7 @@ func (r *ContactoRepository) loadFromExcel() ([]models.RowError, []models.RowDat
 				Field:   "correo",
 				Value:   correo,
 				Error:   "El correo debe contener @",
+				Code:   models.RowErrorCorreo,
 				RowData: &rowData,
 			})
 		}
@@ -654,6 +717,7 @@ if correo != "" && comillasRegex.MatchString(correo) {
 		Field:   "correo",
 		Value:   correo,
 		Error:   "El correo no puede contener ningún tipo de comillas",
+		Code:   models.RowErrorCorreo,
 		RowData: &rowData,
 	})
 }
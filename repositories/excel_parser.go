@@ -0,0 +1,431 @@
+// repositories/excel_parser.go
+package repositories
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"contactos-api/models"
+	"contactos-api/validators"
+
+	"github.com/tealeg/xlsx/v3"
+)
+
+// ParseResult resultado de procesar un archivo de contactos (Excel o CSV)
+type ParseResult struct {
+	Contactos       []models.Contacto
+	LoadErrors      []models.RowError
+	InvalidRowsData []models.RowData
+	TotalRows       int   // Filas de datos procesadas (sin encabezados)
+	SourceRows      []int // Fila del archivo de cada contacto, en el orden de Contactos (0 = sin fila)
+
+	Sheet   string   // Hoja de la que se leyeron las filas (vacío en CSV y vCard)
+	Source  string   // Origen de todos los contactos (archivo y hoja, ver SourceLabel); lo asigna quien conoce el archivo
+	Sources []string // Origen por contacto en el orden de Contactos; si está, tiene prioridad sobre Source
+}
+
+// SourceLabel nombre del origen de un contacto: el archivo y, en Excel, la hoja
+func SourceLabel(fileName, sheet string) string {
+	if sheet == "" {
+		return fileName
+	}
+	return fmt.Sprintf("%s (%s)", fileName, sheet)
+}
+
+// SourcesByClave origen de cada contacto válido, por ClaveCliente
+func (r *ParseResult) SourcesByClave() map[int]string {
+	sources := make(map[int]string, len(r.Contactos))
+	for i, contacto := range r.Contactos {
+		source := r.Source
+		if i < len(r.Sources) && r.Sources[i] != "" {
+			source = r.Sources[i]
+		}
+		if source != "" {
+			sources[contacto.ClaveCliente] = source
+		}
+	}
+	return sources
+}
+
+// RowsByClave fila del archivo de la que salió cada contacto válido, por ClaveCliente
+func (r *ParseResult) RowsByClave() map[int]int {
+	rows := make(map[int]int, len(r.SourceRows))
+	for i, row := range r.SourceRows {
+		if i < len(r.Contactos) && row > 0 {
+			rows[r.Contactos[i].ClaveCliente] = row
+		}
+	}
+	return rows
+}
+
+// Fases del procesamiento reportadas en ParseProgress
+const (
+	ParsePhaseOpening = "abriendo"  // Leyendo el libro completo (Excel); aún no hay filas procesadas
+	ParsePhaseParsing = "validando" // Validando filas
+)
+
+// ParseProgress avance del procesamiento de un archivo
+type ParseProgress struct {
+	Phase     string
+	Processed int
+	Valid     int
+	Invalid   int
+	Total     int // 0 si no se conoce de antemano (CSV)
+}
+
+// ProgressFunc recibe el avance del procesamiento cada progressInterval filas
+type ProgressFunc func(ParseProgress)
+
+// progressInterval cada cuántas filas se reporta avance y se revisa la cancelación
+const progressInterval = 500
+
+// columnasContacto letra de columna del Excel para cada campo
+var columnasContacto = map[string]string{
+	"claveCliente":     "A",
+	"nombre":           "B",
+	"correo":           "C",
+	"telefonoContacto": "D",
+}
+
+// ParseExcelFile procesa un archivo Excel del disco con las reglas de validación por defecto
+func ParseExcelFile(path string) (*ParseResult, error) {
+	return ParseExcelFileContext(context.Background(), path, nil, nil)
+}
+
+// ParseExcelFileContext procesa un archivo Excel del disco con cancelación y avance; las filas
+// se validan con las reglas de validator (nil = reglas por defecto)
+func ParseExcelFileContext(ctx context.Context, path string, validator *validators.ContactoValidator, progress ProgressFunc) (*ParseResult, error) {
+	file, err := openExcel(ctx, progress, func() (*xlsx.File, error) { return xlsx.OpenFile(path) })
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("error abriendo Excel: %w", err)
+	}
+	return parseExcel(ctx, file, validator, progress)
+}
+
+// ParseExcelBinary procesa un archivo Excel recibido en memoria (p. ej. una subida)
+func ParseExcelBinary(data []byte) (*ParseResult, error) {
+	return ParseExcelBinaryContext(context.Background(), data, nil, nil)
+}
+
+// ParseExcelBinaryContext procesa un Excel en memoria con cancelación, avance y las reglas de validator
+func ParseExcelBinaryContext(ctx context.Context, data []byte, validator *validators.ContactoValidator, progress ProgressFunc) (*ParseResult, error) {
+	file, err := openExcel(ctx, progress, func() (*xlsx.File, error) { return xlsx.OpenBinary(data) })
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("error abriendo Excel: %w", err)
+	}
+	return parseExcel(ctx, file, validator, progress)
+}
+
+// openExcel reporta la fase de apertura y abre el libro sin bloquear la cancelación: la librería
+// lee el libro completo antes de entregar filas, así que si ctx se cancela se retorna de inmediato
+// y la lectura en curso se descarta al terminar
+func openExcel(ctx context.Context, progress ProgressFunc, open func() (*xlsx.File, error)) (*xlsx.File, error) {
+	if progress != nil {
+		progress(ParseProgress{Phase: ParsePhaseOpening})
+	}
+	open = recoverOpen(open)
+	if ctx.Done() == nil {
+		return open()
+	}
+
+	type opened struct {
+		file *xlsx.File
+		err  error
+	}
+	done := make(chan opened, 1)
+	go func() {
+		file, err := open()
+		done <- opened{file, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-done:
+		return result.file, result.err
+	}
+}
+
+// recoverOpen convierte en error los panics de la librería con libros dañados o truncados
+func recoverOpen(open func() (*xlsx.File, error)) func() (*xlsx.File, error) {
+	return func() (file *xlsx.File, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				file, err = nil, fmt.Errorf("libro dañado o incompleto: %v", r)
+			}
+		}()
+		return open()
+	}
+}
+
+// ParseCSV procesa un CSV con las mismas columnas y validaciones que el Excel.
+// Acepta coma o punto y coma como separador (Excel en español exporta con ';').
+func ParseCSV(reader io.Reader) (*ParseResult, error) {
+	return ParseCSVContext(context.Background(), reader, nil, nil)
+}
+
+// ParseCSVContext procesa un CSV con cancelación, avance y las reglas de validator
+func ParseCSVContext(ctx context.Context, reader io.Reader, validator *validators.ContactoValidator, progress ProgressFunc) (*ParseResult, error) {
+	buffered := bufio.NewReader(reader)
+
+	// Detectar separador a partir de la primera línea
+	firstLine, err := buffered.Peek(4096)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, fmt.Errorf("error leyendo CSV: %w", err)
+	}
+	if idx := bytes.IndexByte(firstLine, '\n'); idx >= 0 {
+		firstLine = firstLine[:idx]
+	}
+
+	csvReader := csv.NewReader(buffered)
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		csvReader.Comma = ';'
+	}
+
+	parser := newContactoRowParser(ctx, validator, progress, 0)
+	rowIndex := 0
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error leyendo CSV en fila %d: %w", rowIndex+1, err)
+		}
+
+		if rowIndex > 0 { // Saltar header
+			if err := parser.parseRow(rowIndex+1, record); err != nil {
+				return nil, err
+			}
+		}
+		rowIndex++
+	}
+
+	return parser.finish(), nil
+}
+
+// parseExcel procesa la primera hoja de un libro ya abierto
+func parseExcel(ctx context.Context, file *xlsx.File, validator *validators.ContactoValidator, progress ProgressFunc) (*ParseResult, error) {
+	if len(file.Sheets) == 0 {
+		return nil, fmt.Errorf("archivo sin hojas")
+	}
+
+	sheet := file.Sheets[0]
+	parser := newContactoRowParser(ctx, validator, progress, sheet.MaxRow-1)
+
+	rowIndex := 0
+	err := sheet.ForEachRow(func(row *xlsx.Row) error {
+		if rowIndex == 0 { // Saltar header
+			rowIndex++
+			return nil
+		}
+
+		// Obtener celdas
+		var cells []string
+		row.ForEachCell(func(cell *xlsx.Cell) error {
+			if len(cells) < 4 {
+				cells = append(cells, cell.String())
+			}
+			return nil
+		})
+
+		rowIndex++
+		return parser.parseRow(rowIndex, cells)
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("error iterando filas: %w", err)
+	}
+
+	result := parser.finish()
+	result.Sheet = sheet.Name
+	return result, nil
+}
+
+// contactoRowParser valida filas y acumula contactos válidos y errores
+type contactoRowParser struct {
+	result    *ParseResult
+	claves    map[int]int // clave -> fila donde apareció primero
+	validator *validators.ContactoValidator
+
+	ctx      context.Context
+	progress ProgressFunc
+	total    int
+
+	// autoClave acepta filas sin clave (clave 0); la clave se asigna al aplicar la importación
+	autoClave bool
+}
+
+// newContactoRowParser crea el parser; validator nil usa las reglas por defecto
+func newContactoRowParser(ctx context.Context, validator *validators.ContactoValidator, progress ProgressFunc, total int) *contactoRowParser {
+	if total < 0 {
+		total = 0
+	}
+	if validator == nil {
+		validator = validators.NewContactoValidator()
+	}
+	return &contactoRowParser{
+		result: &ParseResult{
+			Contactos:       make([]models.Contacto, 0),
+			LoadErrors:      make([]models.RowError, 0),
+			InvalidRowsData: make([]models.RowData, 0),
+		},
+		claves:    make(map[int]int),
+		validator: validator,
+		ctx:       ctx,
+		progress:  progress,
+		total:     total,
+	}
+}
+
+// parseRow valida una fila de datos; currentRow es el número de fila en el archivo.
+// Retorna error solo si el contexto fue cancelado.
+func (p *contactoRowParser) parseRow(currentRow int, rawCells []string) error {
+	p.validateRow(currentRow, rawCells)
+
+	if p.result.TotalRows%progressInterval == 0 {
+		if err := p.ctx.Err(); err != nil {
+			return err
+		}
+		p.reportProgress()
+	}
+	return nil
+}
+
+// finish reporta el avance final y retorna el resultado
+func (p *contactoRowParser) finish() *ParseResult {
+	p.reportProgress()
+	return p.result
+}
+
+func (p *contactoRowParser) reportProgress() {
+	if p.progress == nil {
+		return
+	}
+	p.progress(ParseProgress{
+		Phase:     ParsePhaseParsing,
+		Processed: p.result.TotalRows,
+		Valid:     len(p.result.Contactos),
+		Invalid:   len(p.result.InvalidRowsData),
+		Total:     p.total,
+	})
+}
+
+// rejectRow registra una fila que no se pudo leer como contacto (error de estructura)
+func (p *contactoRowParser) rejectRow(rowData models.RowData, mensaje string) {
+	rowData.AddError()
+	p.result.InvalidRowsData = append(p.result.InvalidRowsData, rowData)
+	p.result.LoadErrors = append(p.result.LoadErrors, models.RowError{
+		Row:     rowData.Row,
+		Column:  "general",
+		Field:   "estructura",
+		Error:   mensaje,
+		Code:    models.RowErrorEstructura,
+		RowData: &rowData,
+	})
+}
+
+// validateRow aplica las validaciones de carga a una fila
+func (p *contactoRowParser) validateRow(currentRow int, rawCells []string) {
+	p.result.TotalRows++
+
+	var cells [4]string
+	for i := 0; i < len(rawCells) && i < 4; i++ {
+		cells[i] = strings.TrimSpace(rawCells[i])
+	}
+
+	rowData := models.RowData{
+		Row:              currentRow,
+		ClaveCliente:     cells[0],
+		Nombre:           cells[1],
+		Correo:           cells[2],
+		TelefonoContacto: cells[3],
+	}
+
+	if len(rawCells) < 4 {
+		// Fila incompleta, agregar error
+		p.rejectRow(rowData, "Fila incompleta")
+		return
+	}
+
+	claveStr, nombre, correo, telefono := cells[0], cells[1], cells[2], cells[3]
+	var rowErrors []models.RowError
+	addError := func(field, value, code, mensaje string) {
+		rowData.AddError()
+		column := columnasContacto[field]
+		if column == "" {
+			column = "general"
+		}
+		rowErrors = append(rowErrors, models.RowError{
+			Row: currentRow, Column: column, Field: field, Value: value, Error: mensaje, Code: code,
+		})
+	}
+
+	// Validaciones básicas
+	if (claveStr == "" && !p.autoClave) || nombre == "" || correo == "" || telefono == "" {
+		addError("general", "", models.RowErrorVacio, "Campos vacíos")
+	}
+
+	// Validar clave cliente
+	clave := 0
+	if claveStr != "" {
+		if c, err := strconv.Atoi(claveStr); err != nil || c <= 0 {
+			addError("claveCliente", claveStr, models.RowErrorNumerico, "Clave inválida")
+		} else if filaPrevia, duplicada := p.claves[c]; duplicada {
+			addError("claveCliente", claveStr, models.RowErrorDuplicado, fmt.Sprintf("La clave cliente %d ya existe en la fila %d", c, filaPrevia))
+		} else {
+			clave = c
+		}
+	}
+
+	// Validar teléfono con la regla activa (la misma que aplica la plantilla de importación)
+	if telefono != "" && p.validator.ValidarTelefono(telefono) != nil {
+		code := models.RowErrorNumerico
+		if len(telefono) != p.validator.Config().TelefonoDigitos {
+			code = models.RowErrorLongitud
+		}
+		addError("telefonoContacto", telefono, code, p.validator.TelefonoMensaje())
+	}
+
+	// Validar correo básico
+	if correo != "" && !strings.Contains(correo, "@") {
+		addError("correo", correo, models.RowErrorCorreo, "Correo sin @")
+	}
+
+	if rowData.HasErrors {
+		p.result.InvalidRowsData = append(p.result.InvalidRowsData, rowData)
+		for i := range rowErrors {
+			rowErrors[i].RowData = &rowData
+		}
+		p.result.LoadErrors = append(p.result.LoadErrors, rowErrors...)
+		return
+	}
+
+	// Crear contacto válido
+	if clave != 0 {
+		p.claves[clave] = currentRow
+	}
+	p.result.Contactos = append(p.result.Contactos, models.Contacto{
+		ClaveCliente:     clave,
+		Nombre:           nombre,
+		Correo:           correo,
+		TelefonoContacto: telefono,
+	})
+	p.result.SourceRows = append(p.result.SourceRows, currentRow)
+}
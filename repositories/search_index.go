@@ -0,0 +1,290 @@
+// repositories/search_index.go
+package repositories
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"contactos-api/models"
+	"contactos-api/utils"
+)
+
+// SearchKeys campos de un contacto en forma normalizada para buscar
+type SearchKeys struct {
+	Nombre   string
+	Correo   string
+	Telefono string
+	Clave    string
+}
+
+// NewSearchKeys normaliza los campos buscables de un contacto
+func NewSearchKeys(contacto models.Contacto) SearchKeys {
+	return SearchKeys{
+		Nombre:   utils.NormalizeText(contacto.Nombre),
+		Correo:   utils.NormalizeText(contacto.Correo),
+		Telefono: utils.NormalizeText(contacto.TelefonoContacto),
+		Clave:    strconv.Itoa(contacto.ClaveCliente),
+	}
+}
+
+// SearchTerms separa una consulta en términos normalizados
+func SearchTerms(query string) []string {
+	return strings.Fields(utils.NormalizeText(query))
+}
+
+// Matches indica si el término (ya normalizado) aparece en algún campo
+func (k SearchKeys) Matches(term string) bool {
+	return strings.Contains(k.Nombre, term) ||
+		strings.Contains(k.Correo, term) ||
+		strings.Contains(k.Telefono, term) ||
+		strings.Contains(k.Clave, term)
+}
+
+// MatchesAll indica si todos los términos aparecen (cada uno en cualquier campo)
+func (k SearchKeys) MatchesAll(terms []string) bool {
+	for _, term := range terms {
+		if !k.Matches(term) {
+			return false
+		}
+	}
+	return true
+}
+
+// trigram clave de las listas de postings: 3 bytes del texto normalizado empaquetados
+type trigram uint32
+
+func makeTrigram(text string, i int) trigram {
+	return trigram(text[i])<<16 | trigram(text[i+1])<<8 | trigram(text[i+2])
+}
+
+// indexDoc contacto indexado; los docs vivos conservan el orden de los contactos
+type indexDoc struct {
+	contacto models.Contacto
+	keys     SearchKeys
+	alive    bool
+}
+
+// searchIndex índice invertido de trigramas sobre nombre, correo, teléfono y clave.
+// Se actualiza en cada mutación; una subcadena de 3 o más bytes solo puede estar en los
+// documentos que contienen todos sus trigramas, así que basta intersectar listas y verificar.
+type searchIndex struct {
+	docs     []indexDoc
+	byClave  map[int]int32
+	postings map[trigram][]int32 // Listas ordenadas de docs
+	dead     int
+
+	buf []trigram // Reutilizado al indexar (solo con el lock de escritura)
+}
+
+func newSearchIndex(contactos []models.Contacto) *searchIndex {
+	index := &searchIndex{
+		docs:     make([]indexDoc, 0, len(contactos)),
+		byClave:  make(map[int]int32, len(contactos)),
+		postings: make(map[trigram][]int32),
+	}
+	for _, contacto := range contactos {
+		index.add(contacto)
+	}
+	return index
+}
+
+// add indexa un contacto nuevo al final, o reemplaza en su lugar uno existente
+func (ix *searchIndex) add(contacto models.Contacto) {
+	keys := NewSearchKeys(contacto)
+
+	if id, exists := ix.byClave[contacto.ClaveCliente]; exists {
+		previous := docTrigrams(nil, ix.docs[id].keys)
+		current := docTrigrams(ix.buf[:0], keys)
+		ix.buf = current
+
+		// Ambas listas están ordenadas: recorrerlas juntas da las altas y bajas
+		i, j := 0, 0
+		for i < len(previous) || j < len(current) {
+			switch {
+			case j == len(current) || (i < len(previous) && previous[i] < current[j]):
+				ix.postings[previous[i]] = removePosting(ix.postings[previous[i]], id)
+				i++
+			case i == len(previous) || current[j] < previous[i]:
+				ix.postings[current[j]] = insertPosting(ix.postings[current[j]], id)
+				j++
+			default:
+				i++
+				j++
+			}
+		}
+		ix.docs[id].contacto = contacto
+		ix.docs[id].keys = keys
+		return
+	}
+
+	// Los ids crecen: agregar al final mantiene las listas ordenadas
+	id := int32(len(ix.docs))
+	ix.docs = append(ix.docs, indexDoc{contacto: contacto, keys: keys, alive: true})
+	ix.byClave[contacto.ClaveCliente] = id
+	ix.buf = docTrigrams(ix.buf[:0], keys)
+	for _, t := range ix.buf {
+		ix.postings[t] = append(ix.postings[t], id)
+	}
+}
+
+// remove quita un contacto; si se acumulan muchos huecos se compacta el índice
+func (ix *searchIndex) remove(claveCliente int) {
+	id, exists := ix.byClave[claveCliente]
+	if !exists {
+		return
+	}
+
+	ix.buf = docTrigrams(ix.buf[:0], ix.docs[id].keys)
+	for _, t := range ix.buf {
+		if postings := removePosting(ix.postings[t], id); len(postings) > 0 {
+			ix.postings[t] = postings
+		} else {
+			delete(ix.postings, t)
+		}
+	}
+	ix.docs[id] = indexDoc{}
+	delete(ix.byClave, claveCliente)
+	ix.dead++
+
+	if ix.dead > 1000 && ix.dead > len(ix.docs)/2 {
+		live := make([]models.Contacto, 0, len(ix.byClave))
+		for _, doc := range ix.docs {
+			if doc.alive {
+				live = append(live, doc.contacto)
+			}
+		}
+		*ix = *newSearchIndex(live)
+	}
+}
+
+// get retorna las claves del contacto, normalizándolas si no estuviera indexado
+func (ix *searchIndex) get(contacto models.Contacto) SearchKeys {
+	if id, ok := ix.byClave[contacto.ClaveCliente]; ok {
+		return ix.docs[id].keys
+	}
+	return NewSearchKeys(contacto)
+}
+
+// search retorna los contactos que contienen todos los términos, en el orden de los contactos
+func (ix *searchIndex) search(terms []string) []models.Contacto {
+	return ix.filter(terms, func(_ models.Contacto, keys SearchKeys) bool {
+		return keys.MatchesAll(terms)
+	})
+}
+
+// filter retorna los contactos que cumplen match, en el orden de los contactos.
+// required son subcadenas que todo resultado contiene en algún campo: las de 3 o más bytes
+// reducen los candidatos con las listas de postings; si no hay ninguna se recorren todos.
+func (ix *searchIndex) filter(required []string, match func(models.Contacto, SearchKeys) bool) []models.Contacto {
+	var candidates []int32
+	filtered := false
+	for _, term := range required {
+		if len(term) < 3 {
+			continue // Términos cortos: solo se verifican
+		}
+		for _, t := range termTrigrams(term) {
+			postings := ix.postings[t]
+			if !filtered {
+				candidates = postings
+				filtered = true
+			} else {
+				candidates = intersectPostings(candidates, postings)
+			}
+			if len(candidates) == 0 {
+				return []models.Contacto{}
+			}
+		}
+	}
+
+	resultados := make([]models.Contacto, 0)
+	if !filtered {
+		for _, doc := range ix.docs {
+			if doc.alive && match(doc.contacto, doc.keys) {
+				resultados = append(resultados, doc.contacto)
+			}
+		}
+		return resultados
+	}
+
+	for _, id := range candidates {
+		if doc := ix.docs[id]; match(doc.contacto, doc.keys) {
+			resultados = append(resultados, doc.contacto)
+		}
+	}
+	return resultados
+}
+
+// size número de contactos indexados y de trigramas distintos
+func (ix *searchIndex) size() (int, int) {
+	return len(ix.byClave), len(ix.postings)
+}
+
+// docTrigrams agrega a buf los trigramas distintos de todos los campos, ordenados
+func docTrigrams(buf []trigram, keys SearchKeys) []trigram {
+	for _, field := range [...]string{keys.Nombre, keys.Correo, keys.Telefono, keys.Clave} {
+		buf = appendTrigrams(buf, field)
+	}
+	return uniqueTrigrams(buf)
+}
+
+// termTrigrams trigramas distintos de un término
+func termTrigrams(term string) []trigram {
+	return uniqueTrigrams(appendTrigrams(nil, term))
+}
+
+func appendTrigrams(buf []trigram, text string) []trigram {
+	for i := 0; i+3 <= len(text); i++ {
+		buf = append(buf, makeTrigram(text, i))
+	}
+	return buf
+}
+
+// uniqueTrigrams ordena y quita repetidos en el mismo slice
+func uniqueTrigrams(trigrams []trigram) []trigram {
+	slices.Sort(trigrams)
+	return slices.Compact(trigrams)
+}
+
+// intersectPostings intersección de dos listas ordenadas
+func intersectPostings(a, b []int32) []int32 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	result := make([]int32, 0, len(a))
+	j := 0
+	for _, id := range a {
+		for j < len(b) && b[j] < id {
+			j++
+		}
+		if j == len(b) {
+			break
+		}
+		if b[j] == id {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// insertPosting inserta un id manteniendo el orden
+func insertPosting(postings []int32, id int32) []int32 {
+	i := sort.Search(len(postings), func(i int) bool { return postings[i] >= id })
+	if i < len(postings) && postings[i] == id {
+		return postings
+	}
+	postings = append(postings, 0)
+	copy(postings[i+1:], postings[i:])
+	postings[i] = id
+	return postings
+}
+
+// removePosting quita un id de una lista ordenada
+func removePosting(postings []int32, id int32) []int32 {
+	i := sort.Search(len(postings), func(i int) bool { return postings[i] >= id })
+	if i == len(postings) || postings[i] != id {
+		return postings
+	}
+	return append(postings[:i], postings[i+1:]...)
+}
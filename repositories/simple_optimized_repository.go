@@ -2,14 +2,18 @@
 package repositories
 
 import (
+	"context"
 	"fmt"
-	
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"contactos-api/models"
+	"contactos-api/utils"
+	"contactos-api/validators"
 
 	"github.com/tealeg/xlsx/v3"
 )
@@ -21,12 +25,18 @@ type SimpleOptimizedContactoRepository struct {
 	contactos        []models.Contacto
 	loadErrors       []models.RowError
 	invalidRowsData  []models.RowData
+	sourceRows       map[int]int // ClaveCliente -> fila en el archivo de la última carga
+	sources          map[int]string // ClaveCliente -> archivo (y hoja) del que se cargó o importó
+	validator        *validators.ContactoValidator // Reglas con que se validan las filas al cargar
 	
 	// 🚀 OPTIMIZACIONES BÁSICAS
 	indiceClaveCliente map[int]*models.Contacto
 	indiceCorreo       map[string]*models.Contacto
 	searchCache        map[string][]models.Contacto
 	
+	// Índice invertido de trigramas sobre claves normalizadas (siempre activo)
+	indiceTexto *searchIndex
+	
 	// Configuración
 	useOptimization  bool
 	cacheMaxSize     int
@@ -44,8 +54,14 @@ type SimpleOptimizedContactoRepository struct {
 
 // NewSimpleOptimizedContactoRepository crea repositorio optimizado simple
 func NewSimpleOptimizedContactoRepository(excelFile string) *SimpleOptimizedContactoRepository {
+	return NewSimpleOptimizedContactoRepositoryWithValidator(excelFile, validators.NewContactoValidator())
+}
+
+// NewSimpleOptimizedContactoRepositoryWithValidator crea el repositorio validando la carga con reglas personalizadas
+func NewSimpleOptimizedContactoRepositoryWithValidator(excelFile string, validator *validators.ContactoValidator) *SimpleOptimizedContactoRepository {
 	repo := &SimpleOptimizedContactoRepository{
 		excelFile:       excelFile,
+		validator:       validator,
 		contactos:       make([]models.Contacto, 0),
 		loadErrors:      make([]models.RowError, 0),
 		invalidRowsData: make([]models.RowData, 0),
@@ -63,6 +79,7 @@ func NewSimpleOptimizedContactoRepository(excelFile string) *SimpleOptimizedCont
 	}
 	
 	repo.loadTime = time.Since(startTime)
+	repo.indiceTexto = newSearchIndex(repo.contactos)
 	
 	// Construir índices si hay suficientes contactos
 	if len(repo.contactos) > 100 {
@@ -84,8 +101,20 @@ func (r *SimpleOptimizedContactoRepository) buildBasicIndices() {
 	for i := range r.contactos {
 		contacto := &r.contactos[i]
 		r.indiceClaveCliente[contacto.ClaveCliente] = contacto
-		r.indiceCorreo[strings.ToLower(contacto.Correo)] = contacto
+		r.indiceCorreo[utils.NormalizeText(contacto.Correo)] = contacto
+	}
+}
+
+// rebuildIndices reconstruye los índices, o los descarta si el conjunto es pequeño
+// (el índice de texto se conserva siempre)
+func (r *SimpleOptimizedContactoRepository) rebuildIndices() {
+	r.indiceTexto = newSearchIndex(r.contactos)
+	if len(r.contactos) > 100 {
+		r.buildBasicIndices()
+		return
 	}
+	r.indiceClaveCliente = nil
+	r.indiceCorreo = nil
 }
 
 // 🚀 IMPLEMENTACIÓN DE LA INTERFAZ CON OPTIMIZACIONES
@@ -98,6 +127,32 @@ func (r *SimpleOptimizedContactoRepository) GetAll() ([]models.Contacto, error)
 	return r.contactos, nil
 }
 
+// SetValidator reemplaza las reglas con que se validan las siguientes cargas
+func (r *SimpleOptimizedContactoRepository) SetValidator(validator *validators.ContactoValidator) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.validator = validator
+}
+
+// Sources origen de cada contacto cargado o importado (solo lectura: se reemplaza, no se modifica)
+func (r *SimpleOptimizedContactoRepository) Sources() map[int]string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.sources
+}
+
+// sourceLabel origen de los contactos leídos del Excel del repositorio
+func (r *SimpleOptimizedContactoRepository) sourceLabel(result *ParseResult) string {
+	return SourceLabel(filepath.Base(r.excelFile), result.Sheet)
+}
+
+// SourceRows fila del archivo de la última carga de cada contacto (solo lectura)
+func (r *SimpleOptimizedContactoRepository) SourceRows() map[int]int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.sourceRows
+}
+
 func (r *SimpleOptimizedContactoRepository) GetByID(claveCliente int) (*models.Contacto, error) {
 	// Usar índice si está disponible
 	if r.useOptimization && r.indiceClaveCliente != nil {
@@ -151,7 +206,7 @@ func (r *SimpleOptimizedContactoRepository) Search(criteria *models.ContactoDTO)
 	} else if criteria.Correo != "" && r.indiceCorreo != nil {
 		// Búsqueda optimizada por correo
 		r.mu.RLock()
-		if contacto, exists := r.indiceCorreo[strings.ToLower(criteria.Correo)]; exists {
+		if contacto, exists := r.indiceCorreo[utils.NormalizeText(criteria.Correo)]; exists {
 			resultados = []models.Contacto{*contacto}
 		}
 		r.mu.RUnlock()
@@ -174,12 +229,19 @@ func (r *SimpleOptimizedContactoRepository) Search(criteria *models.ContactoDTO)
 	return resultados, nil
 }
 
-// sequentialSearch búsqueda secuencial para criterios múltiples
+// sequentialSearch búsqueda secuencial para criterios múltiples sobre las claves normalizadas
 func (r *SimpleOptimizedContactoRepository) sequentialSearch(criteria *models.ContactoDTO) []models.Contacto {
 	var resultados []models.Contacto
 	
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	
+	nombre := utils.NormalizeText(criteria.Nombre)
+	correo := utils.NormalizeText(criteria.Correo)
+	
 	for _, contacto := range r.contactos {
 		match := true
+		keys := r.indiceTexto.get(contacto)
 		
 		if criteria.ClaveCliente != "" {
 			if clave, err := strconv.Atoi(criteria.ClaveCliente); err != nil || contacto.ClaveCliente != clave {
@@ -187,17 +249,11 @@ func (r *SimpleOptimizedContactoRepository) sequentialSearch(criteria *models.Co
 			}
 		}
 		
-		if criteria.Nombre != "" && !strings.Contains(
-			strings.ToLower(contacto.Nombre), 
-			strings.ToLower(criteria.Nombre),
-		) {
+		if nombre != "" && !strings.Contains(keys.Nombre, nombre) {
 			match = false
 		}
 		
-		if criteria.Correo != "" && !strings.Contains(
-			strings.ToLower(contacto.Correo), 
-			strings.ToLower(criteria.Correo),
-		) {
+		if correo != "" && !strings.Contains(keys.Correo, correo) {
 			match = false
 		}
 		
@@ -216,6 +272,25 @@ func (r *SimpleOptimizedContactoRepository) sequentialSearch(criteria *models.Co
 	return resultados
 }
 
+// SearchText busca los términos normalizados (todos deben aparecer) en nombre, correo,
+// teléfono y clave usando el índice invertido
+func (r *SimpleOptimizedContactoRepository) SearchText(query string) ([]models.Contacto, error) {
+	terms := SearchTerms(query)
+	
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	
+	return r.indiceTexto.search(terms), nil
+}
+
+// FilterText aplica un filtro arbitrario sobre las claves normalizadas del índice
+func (r *SimpleOptimizedContactoRepository) FilterText(required []string, match func(models.Contacto, SearchKeys) bool) ([]models.Contacto, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	
+	return r.indiceTexto.filter(required, match), nil
+}
+
 func (r *SimpleOptimizedContactoRepository) Create(contacto *models.Contacto) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -236,8 +311,9 @@ func (r *SimpleOptimizedContactoRepository) Create(contacto *models.Contacto) er
 		r.indiceClaveCliente[contacto.ClaveCliente] = nuevoContacto
 	}
 	if r.indiceCorreo != nil {
-		r.indiceCorreo[strings.ToLower(contacto.Correo)] = nuevoContacto
+		r.indiceCorreo[utils.NormalizeText(contacto.Correo)] = nuevoContacto
 	}
+	r.indiceTexto.add(*contacto)
 	
 	// Limpiar cache
 	r.clearCache()
@@ -252,7 +328,14 @@ func (r *SimpleOptimizedContactoRepository) Update(contacto *models.Contacto) er
 	// Encontrar contacto usando índice
 	if r.indiceClaveCliente != nil {
 		if existente, exists := r.indiceClaveCliente[contacto.ClaveCliente]; exists {
+			if r.indiceCorreo != nil {
+				if anterior := utils.NormalizeText(existente.Correo); r.indiceCorreo[anterior] == existente {
+					delete(r.indiceCorreo, anterior)
+				}
+				r.indiceCorreo[utils.NormalizeText(contacto.Correo)] = existente
+			}
 			*existente = *contacto
+			r.indiceTexto.add(*contacto)
 			r.clearCache()
 			return r.saveToExcel()
 		}
@@ -261,6 +344,7 @@ func (r *SimpleOptimizedContactoRepository) Update(contacto *models.Contacto) er
 		for i, c := range r.contactos {
 			if c.ClaveCliente == contacto.ClaveCliente {
 				r.contactos[i] = *contacto
+				r.indiceTexto.add(*contacto)
 				r.clearCache()
 				return r.saveToExcel()
 			}
@@ -287,16 +371,15 @@ func (r *SimpleOptimizedContactoRepository) Delete(claveCliente int) error {
 		return fmt.Errorf("contacto con clave %d no encontrado", claveCliente)
 	}
 	
-	// Eliminar del slice
-	r.contactos = append(r.contactos[:indice], r.contactos[indice+1:]...)
+	// Eliminar en una copia: GetAll entrega el slice sin copiar y desplazarlo en su lugar
+	// alteraría los listados que se están recorriendo o paginando
+	contactos := make([]models.Contacto, 0, len(r.contactos)-1)
+	contactos = append(contactos, r.contactos[:indice]...)
+	r.contactos = append(contactos, r.contactos[indice+1:]...)
 	
-	// Actualizar índices
+	// Actualizar índices (apuntan al slice anterior)
+	r.indiceTexto.remove(claveCliente)
 	if r.indiceClaveCliente != nil {
-		delete(r.indiceClaveCliente, claveCliente)
-	}
-	
-	// Reconstruir índice de correo (simple)
-	if r.indiceCorreo != nil {
 		r.buildBasicIndices()
 	}
 	
@@ -331,26 +414,124 @@ func (r *SimpleOptimizedContactoRepository) GetInvalidRowsData() []models.RowDat
 }
 
 func (r *SimpleOptimizedContactoRepository) ReloadExcel() ([]models.RowError, []models.RowData, error) {
-	startTime := time.Now()
+	return r.ReloadExcelContext(context.Background(), nil)
+}
+
+// ReloadExcelContext recarga el Excel reportando avance; si se cancela no modifica los datos
+func (r *SimpleOptimizedContactoRepository) ReloadExcelContext(ctx context.Context, progress ProgressFunc) ([]models.RowError, []models.RowData, error) {
+	result, err := r.ParseExcelContext(ctx, progress)
+	if err != nil {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+		return r.loadErrors, r.invalidRowsData, err
+	}
+	
+	r.ApplyReload(result)
+	return result.LoadErrors, result.InvalidRowsData, nil
+}
+
+// ParseExcelContext procesa el Excel con las reglas actuales sin modificar los datos; no toma
+// el lock durante el procesamiento, así que lecturas y escrituras siguen funcionando
+func (r *SimpleOptimizedContactoRepository) ParseExcelContext(ctx context.Context, progress ProgressFunc) (*ParseResult, error) {
 	fmt.Println("🔄 Recargando Excel...")
 	
+	r.mu.RLock()
+	validator := r.validator
+	r.mu.RUnlock()
+	
+	startTime := time.Now()
+	result, err := ParseExcelFileContext(ctx, r.excelFile, validator, progress)
+	if err != nil {
+		return nil, err
+	}
+	result.Source = r.sourceLabel(result)
+	
+	r.mu.Lock()
+	r.loadTime = time.Since(startTime)
+	r.mu.Unlock()
+	return result, nil
+}
+
+// ApplyReload reemplaza los datos por el resultado de ParseExcelContext
+func (r *SimpleOptimizedContactoRepository) ApplyReload(result *ParseResult) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	
-	if err := r.loadFromExcel(); err != nil {
-		return r.loadErrors, r.invalidRowsData, err
-	}
+	r.contactos = result.Contactos
+	r.loadErrors = result.LoadErrors
+	r.invalidRowsData = result.InvalidRowsData
+	r.sourceRows = result.RowsByClave()
+	r.sources = result.SourcesByClave()
 	
 	// Reconstruir índices
-	if len(r.contactos) > 100 {
-		r.buildBasicIndices()
-	}
+	r.rebuildIndices()
 	
 	r.clearCache()
-	r.loadTime = time.Since(startTime)
 	
 	fmt.Printf("✅ Recarga completada en %v\n", r.loadTime)
-	return r.loadErrors, r.invalidRowsData, nil
+}
+
+// 📦 OPERACIONES MASIVAS (un solo guardado en Excel)
+
+// ReplaceAll reemplaza todo el contenido con el resultado de un archivo procesado
+func (r *SimpleOptimizedContactoRepository) ReplaceAll(result *ParseResult) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	
+	r.contactos = append([]models.Contacto(nil), result.Contactos...)
+	r.loadErrors = result.LoadErrors
+	r.invalidRowsData = result.InvalidRowsData
+	r.sourceRows = result.RowsByClave()
+	r.sources = result.SourcesByClave()
+	
+	r.rebuildIndices()
+	r.clearCache()
+	
+	return r.saveToExcel()
+}
+
+// UpsertMany inserta o actualiza varios contactos por ClaveCliente y les registra source como origen
+func (r *SimpleOptimizedContactoRepository) UpsertMany(contactos []models.Contacto, source string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	
+	// Actualizar en una copia: GetAll entrega el slice sin copiar y quien lo recorre sin el
+	// lock (búsquedas, exportación, índices) vería contactos cambiando a mitad del recorrido
+	actualizados := make([]models.Contacto, len(r.contactos), len(r.contactos)+len(contactos))
+	copy(actualizados, r.contactos)
+	
+	posiciones := make(map[int]int, len(actualizados))
+	for i, contacto := range actualizados {
+		posiciones[contacto.ClaveCliente] = i
+	}
+	
+	for _, contacto := range contactos {
+		if i, exists := posiciones[contacto.ClaveCliente]; exists {
+			actualizados[i] = contacto
+			continue
+		}
+		actualizados = append(actualizados, contacto)
+		posiciones[contacto.ClaveCliente] = len(actualizados) - 1
+	}
+	r.contactos = actualizados
+	
+	// Orígenes en un mapa nuevo: Sources entrega el actual sin copiar
+	if source != "" {
+		sources := make(map[int]string, len(r.sources)+len(contactos))
+		for clave, origen := range r.sources {
+			sources[clave] = origen
+		}
+		for _, contacto := range contactos {
+			sources[contacto.ClaveCliente] = source
+		}
+		r.sources = sources
+	}
+	
+	// Los punteros de los índices apuntan al slice anterior
+	r.rebuildIndices()
+	r.clearCache()
+	
+	return r.saveToExcel()
 }
 
 // 🔧 FUNCIONES AUXILIARES
@@ -375,6 +556,10 @@ func (r *SimpleOptimizedContactoRepository) GetStats() map[string]interface{} {
 		cacheHitRate = (float64(r.cacheHits) / float64(r.cacheHits+r.cacheMisses)) * 100
 	}
 	
+	r.mu.RLock()
+	textoDocs, trigramas := r.indiceTexto.size()
+	r.mu.RUnlock()
+	
 	return map[string]interface{}{
 		"contactos_count":    len(r.contactos),
 		"load_time_ms":       r.loadTime.Milliseconds(),
@@ -386,6 +571,8 @@ func (r *SimpleOptimizedContactoRepository) GetStats() map[string]interface{} {
 		"index_sizes": map[string]int{
 			"clave_cliente": len(r.indiceClaveCliente),
 			"correo":        len(r.indiceCorreo),
+			"texto":         textoDocs,
+			"trigramas":     trigramas,
 		},
 	}
 }
@@ -393,142 +580,20 @@ func (r *SimpleOptimizedContactoRepository) GetStats() map[string]interface{} {
 // 📄 CARGA Y GUARDADO OPTIMIZADOS
 
 func (r *SimpleOptimizedContactoRepository) loadFromExcel() error {
-	file, err := xlsx.OpenFile(r.excelFile)
+	result, err := ParseExcelFileContext(context.Background(), r.excelFile, r.validator, nil)
 	if err != nil {
-		return fmt.Errorf("error abriendo Excel: %w", err)
-	}
-
-	if len(file.Sheets) == 0 {
-		return fmt.Errorf("archivo sin hojas")
+		return err
 	}
-
-	sheet := file.Sheets[0]
+	result.Source = r.sourceLabel(result)
 	
-	// Limpiar datos anteriores
-	r.contactos = r.contactos[:0]
-	r.loadErrors = r.loadErrors[:0]
-	r.invalidRowsData = r.invalidRowsData[:0]
+	// Slices nuevos: los reportes previos conservan los suyos
+	r.contactos = result.Contactos
+	r.loadErrors = result.LoadErrors
+	r.invalidRowsData = result.InvalidRowsData
+	r.sourceRows = result.RowsByClave()
+	r.sources = result.SourcesByClave()
 	
-	// Procesar filas
-	rowIndex := 0
-	err = sheet.ForEachRow(func(row *xlsx.Row) error {
-		if rowIndex == 0 { // Saltar header
-			rowIndex++
-			return nil
-		}
-
-		currentRow := rowIndex + 1
-
-		// Obtener celdas
-		var cells [4]string
-		cellIndex := 0
-		row.ForEachCell(func(cell *xlsx.Cell) error {
-			if cellIndex < 4 {
-				cells[cellIndex] = strings.TrimSpace(cell.String())
-				cellIndex++
-			}
-			return nil
-		})
-
-		if cellIndex < 4 {
-			// Fila incompleta, agregar error
-			rowData := models.RowData{
-				ClaveCliente:     cells[0],
-				Nombre:           cells[1],
-				Correo:           cells[2],
-				TelefonoContacto: cells[3],
-				HasErrors:        true,
-				ErrorCount:       1,
-			}
-			
-			r.invalidRowsData = append(r.invalidRowsData, rowData)
-			r.loadErrors = append(r.loadErrors, models.RowError{
-				Row:     currentRow,
-				Column:  "general",
-				Field:   "estructura",
-				Error:   "Fila incompleta",
-				RowData: &rowData,
-			})
-			
-			rowIndex++
-			return nil
-		}
-
-		// Validar y procesar fila completa
-		claveStr, nombre, correo, telefono := cells[0], cells[1], cells[2], cells[3]
-
-		rowData := models.RowData{
-			ClaveCliente:     claveStr,
-			Nombre:           nombre,
-			Correo:           correo,
-			TelefonoContacto: telefono,
-			HasErrors:        false,
-			ErrorCount:       0,
-		}
-
-		var rowErrors []models.RowError
-
-		// Validaciones básicas
-		if claveStr == "" || nombre == "" || correo == "" || telefono == "" {
-			rowData.HasErrors = true
-			rowData.ErrorCount++
-			rowErrors = append(rowErrors, models.RowError{
-				Row: currentRow, Field: "general", Error: "Campos vacíos", RowData: &rowData,
-			})
-		}
-
-		// Validar clave cliente
-		clave := 0
-		if claveStr != "" {
-			if c, err := strconv.Atoi(claveStr); err != nil || c <= 0 {
-				rowData.HasErrors = true
-				rowData.ErrorCount++
-				rowErrors = append(rowErrors, models.RowError{
-					Row: currentRow, Field: "claveCliente", Error: "Clave inválida", RowData: &rowData,
-				})
-			} else {
-				clave = c
-			}
-		}
-
-		// Validar teléfono
-		if telefono != "" && len(telefono) != 10 {
-			rowData.HasErrors = true
-			rowData.ErrorCount++
-			rowErrors = append(rowErrors, models.RowError{
-				Row: currentRow, Field: "telefonoContacto", Error: "Teléfono debe tener 10 dígitos", RowData: &rowData,
-			})
-		}
-
-		// Validar correo básico
-		if correo != "" && !strings.Contains(correo, "@") {
-			rowData.HasErrors = true
-			rowData.ErrorCount++
-			rowErrors = append(rowErrors, models.RowError{
-				Row: currentRow, Field: "correo", Error: "Correo sin @", RowData: &rowData,
-			})
-		}
-
-		r.loadErrors = append(r.loadErrors, rowErrors...)
-
-		if rowData.HasErrors {
-			r.invalidRowsData = append(r.invalidRowsData, rowData)
-		} else {
-			// Crear contacto válido
-			contacto := models.Contacto{
-				ClaveCliente:     clave,
-				Nombre:           nombre,
-				Correo:           correo,
-				TelefonoContacto: telefono,
-			}
-			r.contactos = append(r.contactos, contacto)
-		}
-
-		rowIndex++
-		return nil
-	})
-
-	return err
+	return nil
 }
 
 func (r *SimpleOptimizedContactoRepository) saveToExcel() error {
@@ -554,5 +619,29 @@ func (r *SimpleOptimizedContactoRepository) saveToExcel() error {
 		row.AddCell().Value = contacto.TelefonoContacto
 	}
 
-	return file.Save(r.excelFile)
+	return saveFileAtomic(file, r.excelFile)
+}
+
+// saveFileAtomic escribe el libro en un temporal y lo renombra sobre path: una recarga que lee
+// el archivo al mismo tiempo ve el libro anterior o el nuevo, nunca uno a medio escribir
+func saveFileAtomic(file *xlsx.File, path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".contactos-*.xlsx")
+	if err != nil {
+		return fmt.Errorf("error creando archivo temporal: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // Sin efecto tras el rename
+	
+	if info, statErr := os.Stat(path); statErr == nil {
+		tmp.Chmod(info.Mode().Perm())
+	}
+	if err = file.Write(tmp); err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("error guardando Excel: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
 }
\ No newline at end of file
@@ -0,0 +1,314 @@
+// repositories/vcard_parser.go
+package repositories
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"contactos-api/models"
+	"contactos-api/validators"
+)
+
+// vcardProperty propiedad de una vCard ya desplegada (sin grupo)
+type vcardProperty struct {
+	name   string
+	params map[string][]string
+	value  string
+}
+
+// vcard propiedades de una tarjeta y la línea donde empezó
+type vcard struct {
+	line       int
+	properties []vcardProperty
+}
+
+// ParseVCard procesa tarjetas vCard 2.1/3.0/4.0 mapeando FN, EMAIL y TEL a contactos.
+// Cada tarjeta cuenta como una fila (numerada desde 1) y pasa por las validaciones de carga.
+// Las tarjetas sin clave (X-CLAVE-CLIENTE) se aceptan con clave 0 para asignarla al importar.
+func ParseVCard(reader io.Reader) (*ParseResult, error) {
+	return ParseVCardContext(context.Background(), reader, nil, nil)
+}
+
+// ParseVCardContext procesa tarjetas vCard con cancelación, avance y las reglas de validator
+func ParseVCardContext(ctx context.Context, reader io.Reader, validator *validators.ContactoValidator, progress ProgressFunc) (*ParseResult, error) {
+	lines, err := unfoldVCardLines(reader)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo vCard: %w", err)
+	}
+
+	total := 0
+	for _, line := range lines {
+		if strings.EqualFold(strings.TrimSpace(line), "BEGIN:VCARD") {
+			total++
+		}
+	}
+
+	parser := newContactoRowParser(ctx, validator, progress, total)
+	parser.autoClave = true
+
+	cardIndex := 0
+	var current *vcard
+	finish := func(card *vcard, mensaje string) error {
+		cardIndex++
+		if mensaje != "" {
+			parser.result.TotalRows++
+			parser.rejectRow(models.RowData{Row: cardIndex}, fmt.Sprintf("%s (línea %d)", mensaje, card.line))
+			return nil
+		}
+		return parser.parseRow(cardIndex, card.fields())
+	}
+
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		prop, ok := parseVCardLine(line)
+
+		switch {
+		case ok && prop.name == "BEGIN" && strings.EqualFold(prop.value, "VCARD"):
+			if current != nil {
+				if err := finish(current, "vCard sin END:VCARD"); err != nil {
+					return nil, err
+				}
+			}
+			current = &vcard{line: i + 1}
+		case ok && prop.name == "END" && strings.EqualFold(prop.value, "VCARD"):
+			if current == nil {
+				continue
+			}
+			mensaje := ""
+			if !current.hasContactData() {
+				mensaje = "vCard sin FN, N, EMAIL ni TEL"
+			}
+			if err := finish(current, mensaje); err != nil {
+				return nil, err
+			}
+			current = nil
+		case current != nil && ok:
+			current.properties = append(current.properties, prop)
+		}
+	}
+	if current != nil {
+		if err := finish(current, "vCard sin END:VCARD"); err != nil {
+			return nil, err
+		}
+	}
+
+	if cardIndex == 0 {
+		return nil, fmt.Errorf("el archivo no contiene tarjetas BEGIN:VCARD")
+	}
+
+	return parser.finish(), nil
+}
+
+// unfoldVCardLines lee las líneas y une las continuaciones (líneas que empiezan con espacio o tab)
+func unfoldVCardLines(reader io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) == 0 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// parseVCardLine separa nombre, parámetros y valor de una línea "grupo.NOMBRE;PARAM=x:valor"
+func parseVCardLine(line string) (vcardProperty, bool) {
+	// Buscar el primer ':' fuera de comillas (los parámetros pueden ir entre comillas)
+	colon := -1
+	inQuotes := false
+	for i, r := range line {
+		if r == '"' {
+			inQuotes = !inQuotes
+		} else if r == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+	if colon <= 0 {
+		return vcardProperty{}, false
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	name := strings.ToUpper(parts[0])
+	if dot := strings.LastIndex(name, "."); dot >= 0 {
+		name = name[dot+1:]
+	}
+
+	prop := vcardProperty{name: name, params: make(map[string][]string), value: line[colon+1:]}
+	for _, param := range parts[1:] {
+		key, value, found := strings.Cut(param, "=")
+		if !found {
+			// vCard 2.1: parámetros sin nombre (p. ej. "TEL;CELL")
+			key, value = "TYPE", param
+		}
+		key = strings.ToUpper(key)
+		for _, v := range strings.Split(strings.Trim(value, `"`), ",") {
+			prop.params[key] = append(prop.params[key], strings.ToLower(v))
+		}
+	}
+	return prop, true
+}
+
+// hasContactData indica si la tarjeta tiene algún dato mapeable a un contacto
+func (c *vcard) hasContactData() bool {
+	for _, prop := range c.properties {
+		switch prop.name {
+		case "FN", "N", "EMAIL", "TEL":
+			return true
+		}
+	}
+	return false
+}
+
+// fields mapea la tarjeta a las celdas clave, nombre, correo y teléfono
+func (c *vcard) fields() []string {
+	var clave, nombre, nombreN string
+	var correo, telefono *vcardProperty
+
+	for i := range c.properties {
+		prop := &c.properties[i]
+		switch prop.name {
+		case "X-CLAVE-CLIENTE":
+			clave = unescapeVCard(prop.value)
+		case "FN":
+			nombre = unescapeVCard(prop.value)
+		case "N":
+			nombreN = nameFromN(prop.value)
+		case "EMAIL":
+			if correo == nil || (prop.preferred() && !correo.preferred()) {
+				correo = prop
+			}
+		case "TEL":
+			if telefono == nil || prop.telRank() > telefono.telRank() {
+				telefono = prop
+			}
+		}
+	}
+
+	if nombre == "" {
+		nombre = nombreN
+	}
+	correoValue, telefonoValue := "", ""
+	if correo != nil {
+		correoValue = strings.TrimPrefix(unescapeVCard(correo.value), "mailto:")
+	}
+	if telefono != nil {
+		telefonoValue = normalizeVCardPhone(unescapeVCard(telefono.value))
+	}
+
+	return []string{clave, nombre, correoValue, telefonoValue}
+}
+
+// preferred indica si la propiedad está marcada como preferida (TYPE=pref o PREF=1)
+func (p *vcardProperty) preferred() bool {
+	for _, t := range p.params["TYPE"] {
+		if t == "pref" {
+			return true
+		}
+	}
+	return len(p.params["PREF"]) > 0
+}
+
+// telRank prioridad del teléfono: preferido, luego celular
+func (p *vcardProperty) telRank() int {
+	rank := 0
+	if p.preferred() {
+		rank += 2
+	}
+	for _, t := range p.params["TYPE"] {
+		if t == "cell" {
+			rank++
+		}
+	}
+	return rank
+}
+
+// nameFromN arma "nombres apellidos" a partir de N:apellidos;nombres;adicionales;prefijo;sufijo
+func nameFromN(value string) string {
+	parts := splitVCardValue(value)
+	var nombre []string
+	for _, i := range []int{3, 1, 2, 0, 4} {
+		if i < len(parts) && strings.TrimSpace(parts[i]) != "" {
+			nombre = append(nombre, strings.TrimSpace(parts[i]))
+		}
+	}
+	return strings.Join(nombre, " ")
+}
+
+// normalizeVCardPhone quita separadores y la lada internacional de México (+52 / +521).
+// Otros caracteres se conservan para que la validación los reporte.
+func normalizeVCardPhone(value string) string {
+	value = strings.TrimPrefix(value, "tel:")
+	telefono := strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "", "+", "").Replace(value)
+
+	switch {
+	case len(telefono) == 12 && strings.HasPrefix(telefono, "52"):
+		return telefono[2:]
+	case len(telefono) == 13 && strings.HasPrefix(telefono, "521"):
+		return telefono[3:]
+	}
+	return telefono
+}
+
+// splitVCardValue separa componentes por ';' respetando los escapes
+func splitVCardValue(value string) []string {
+	var parts []string
+	var current strings.Builder
+	escaped := false
+	for _, r := range value {
+		switch {
+		case escaped:
+			current.WriteRune('\\')
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ';':
+			parts = append(parts, unescapeVCard(current.String()))
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	return append(parts, unescapeVCard(current.String()))
+}
+
+// unescapeVCard revierte los escapes de texto de vCard (\n, \, \; \\)
+func unescapeVCard(value string) string {
+	if !strings.Contains(value, `\`) {
+		return strings.TrimSpace(value)
+	}
+
+	var out strings.Builder
+	escaped := false
+	for _, r := range value {
+		if escaped {
+			if r == 'n' || r == 'N' {
+				out.WriteRune('\n')
+			} else {
+				out.WriteRune(r)
+			}
+			escaped = false
+			continue
+		}
+		if r == '\\' {
+			escaped = true
+			continue
+		}
+		out.WriteRune(r)
+	}
+	return strings.TrimSpace(out.String())
+}
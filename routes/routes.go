@@ -24,14 +24,42 @@ func SetupRoutes(contactoService services.ContactoServiceInterface) *mux.Router
 	contactos.HandleFunc("/paginated", contactoHandler.GetContactosPaginated).Methods("GET")
 	contactos.HandleFunc("/search", contactoHandler.SearchContactosPaginated).Methods("GET")
 	contactos.HandleFunc("/count", contactoHandler.GetContactosCount).Methods("GET")
+	contactos.HandleFunc("/buscar", contactoHandler.SearchContactos).Methods("GET")
+	contactos.HandleFunc("/suggest", contactoHandler.SuggestContactos).Methods("GET")
+	contactos.HandleFunc("/export", contactoHandler.ExportContactos).Methods("GET")
+	contactos.HandleFunc("/template.xlsx", contactoHandler.GetImportTemplate).Methods("GET")
 	
 	// ✅ RUTAS DE VALIDACIÓN Y SISTEMA (corregidas)
 	contactos.HandleFunc("/stats", contactoHandler.GetContactoStats).Methods("GET")
 	contactos.HandleFunc("/validation", contactoHandler.GetExcelValidationReport).Methods("GET")
+	contactos.HandleFunc("/validation/diff", contactoHandler.GetValidationDiff).Methods("GET")
+	contactos.HandleFunc("/validation/previous", contactoHandler.GetPreviousValidationReport).Methods("GET")
+	contactos.HandleFunc("/validation/rules/test", contactoHandler.TestValidationRules).Methods("POST")
 	contactos.HandleFunc("/errors", contactoHandler.GetValidationErrors).Methods("GET")
 	contactos.HandleFunc("/invalid-data", contactoHandler.GetInvalidContactsForCorrection).Methods("GET")
+	contactos.HandleFunc("/invalid-data/export.xlsx", contactoHandler.ExportInvalidDataWorkbook).Methods("GET")
 	contactos.HandleFunc("/con-validacion", contactoHandler.GetContactosConEstadoValidacion).Methods("GET")
 	contactos.HandleFunc("/reload", contactoHandler.ReloadExcel).Methods("POST")
+	contactos.HandleFunc("/import", contactoHandler.ImportContactos).Methods("POST")
+	contactos.HandleFunc("/import/{token:[a-f0-9]+}/apply", contactoHandler.ApplyImportPreview).Methods("POST")
+	contactos.HandleFunc("/transform", contactoHandler.TransformContactos).Methods("POST")
+	contactos.HandleFunc("/transform/{id:[a-f0-9]+}/undo", contactoHandler.UndoTransform).Methods("POST")
+	
+	// 📡 STREAM DE CAMBIOS (Server-Sent Events)
+	contactos.HandleFunc("/events", contactoHandler.StreamEvents).Methods("GET")
+	
+	// 🔔 WEBHOOKS
+	contactos.HandleFunc("/webhooks", contactoHandler.ListWebhooks).Methods("GET")
+	contactos.HandleFunc("/webhooks", contactoHandler.CreateWebhook).Methods("POST")
+	contactos.HandleFunc("/webhooks/deliveries", contactoHandler.ListWebhookDeliveries).Methods("GET")
+	contactos.HandleFunc("/webhooks/deliveries/{id:[a-f0-9]+}/retry", contactoHandler.RetryWebhookDelivery).Methods("POST")
+	contactos.HandleFunc("/webhooks/{id:[a-f0-9]+}", contactoHandler.DeleteWebhook).Methods("DELETE")
+	
+	// ⏳ TRABAJOS EN SEGUNDO PLANO (importaciones y recargas; async=false las hace síncronas)
+	contactos.HandleFunc("/jobs", contactoHandler.ListJobs).Methods("GET")
+	contactos.HandleFunc("/jobs/{id:[a-f0-9]+}", contactoHandler.GetJob).Methods("GET")
+	contactos.HandleFunc("/jobs/{id:[a-f0-9]+}/cancel", contactoHandler.CancelJob).Methods("POST")
+	contactos.HandleFunc("/jobs/{id:[a-f0-9]+}/report", contactoHandler.GetJobResult).Methods("GET")
 	
 	// 📊 RUTAS BÁSICAS - MODIFICADAS para aceptar claves alfanuméricas
 	contactos.HandleFunc("", contactoHandler.GetAllContactos).Methods("GET")
@@ -40,9 +68,7 @@ func SetupRoutes(contactoService services.ContactoServiceInterface) *mux.Router
 	contactos.HandleFunc("/{clave:[A-Za-z0-9._-]+}", contactoHandler.GetContactoByID).Methods("GET")
 	contactos.HandleFunc("/{clave:[A-Za-z0-9._-]+}", contactoHandler.UpdateContacto).Methods("PUT")
 	contactos.HandleFunc("/{clave:[A-Za-z0-9._-]+}", contactoHandler.DeleteContacto).Methods("DELETE")
-
-	// Rutas adicionales existentes
-	contactos.HandleFunc("/buscar", contactoHandler.SearchContactos).Methods("GET")
+	contactos.HandleFunc("/{clave:[A-Za-z0-9._-]+}/vcard", contactoHandler.ExportContactoVCard).Methods("GET")
 
 	// Health check
 	api.HandleFunc("/health", contactoHandler.HealthCheck).Methods("GET")
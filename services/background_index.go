@@ -0,0 +1,97 @@
+// services/background_index.go
+package services
+
+import (
+	"sync"
+
+	"contactos-api/models"
+)
+
+// backgroundIndex índice derivado de los contactos (difuso, autocompletado) que se reconstruye en
+// segundo plano en cuanto cambian los datos, en lugar de dentro de la primera petición que lo usa.
+// Las peticiones solo esperan si la reconstrucción de su versión todavía no termina.
+type backgroundIndex[T any] struct {
+	build func(version uint64, contactos []models.Contacto) *T
+
+	mu       sync.Mutex
+	ready    *sync.Cond
+	index    *T
+	version  uint64 // Versión de los datos con que se construyó index
+	building bool
+
+	// Datos más nuevos que llegaron durante una reconstrucción; se indexan al terminar
+	nextVersion   uint64
+	nextContactos []models.Contacto
+}
+
+func newBackgroundIndex[T any](build func(uint64, []models.Contacto) *T) *backgroundIndex[T] {
+	b := &backgroundIndex[T]{build: build}
+	b.ready = sync.NewCond(&b.mu)
+	return b
+}
+
+// get retorna un índice construido con datos de la versión indicada o más nuevos; el primer uso
+// lo construye y desde entonces refresh lo mantiene al día
+func (b *backgroundIndex[T]) get(version uint64, contactos []models.Contacto) *T {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for b.index == nil || b.version < version {
+		if !b.building {
+			b.start(version, contactos)
+		}
+		b.ready.Wait()
+	}
+	return b.index
+}
+
+// refresh inicia la reconstrucción tras una mutación si el índice ya se usó; si hay una en curso,
+// los datos quedan pendientes y se indexan al terminar (una ráfaga de cambios cuesta dos
+// reconstrucciones, no una por cambio)
+func (b *backgroundIndex[T]) refresh(version uint64, contactos []models.Contacto) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case b.index == nil || b.version >= version:
+	case b.building:
+		if version > b.nextVersion {
+			b.nextVersion, b.nextContactos = version, contactos
+		}
+	default:
+		b.start(version, contactos)
+	}
+}
+
+// start construye en otra goroutine (requiere mu tomado)
+func (b *backgroundIndex[T]) start(version uint64, contactos []models.Contacto) {
+	b.building = true
+	go func() {
+		index := b.build(version, contactos)
+
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.building = false
+		if b.index == nil || version > b.version {
+			b.index = index
+			b.version = version
+		}
+		if b.nextVersion > b.version {
+			b.start(b.nextVersion, b.nextContactos)
+		}
+		b.nextContactos = nil
+		b.ready.Broadcast()
+	}()
+}
+
+// dataChanged registra una mutación: incrementa version, lo que invalida vistas previas y
+// cachés, y pone a reconstruir los índices en uso (requiere loadMu tomado)
+func (s *ContactoService) dataChanged() {
+	version := s.version.Add(1)
+	contactos, err := s.repo.GetAll()
+	if err != nil {
+		return
+	}
+	s.fuzzy.refresh(version, contactos)
+	s.suggest.refresh(version, contactos)
+}
@@ -1,15 +1,20 @@
 package services
 
 import (
+	"context"
 	"fmt"
-	"strings"
-	"time"
+	"io"
+	"sync"
+	"sync/atomic"
 
 	"contactos-api/models"
 	"contactos-api/repositories"
 	"contactos-api/validators"
 )
 
+// maxReloadAttempts veces que una recarga procesa el Excel si los datos cambian mientras lo
+// lee; el último intento se hace con loadMu tomado
+const maxReloadAttempts = 3
 
 // ContactoServiceInterface define la interfaz para el servicio de contactos
 type ContactoServiceInterface interface {
@@ -19,31 +24,146 @@ type ContactoServiceInterface interface {
 	UpdateContacto(claveCliente int, request *models.ContactoRequest) (*models.Contacto, []models.ErrorResponse, error)
 	DeleteContacto(claveCliente int) error
 	SearchContactos(criteria *models.ContactoDTO) ([]models.Contacto, []models.ErrorResponse, error)
-	GetExcelValidationReport() (*models.ExcelValidationReport, error)
+	GetExcelValidationReport(topErrors int) (*models.ExcelValidationReport, error)
 	ReloadExcel() (*models.ExcelValidationReport, error)
+	GetValidationDiff() (*models.ValidationDiff, error)
+	GetPreviousValidationReport() (*models.ExcelValidationReport, error)
+	ImportContactos(fileName string, data []byte, mode string) (*models.ImportResult, []models.ErrorResponse, error)
+	PreviewImport(fileName string, data []byte, mode string) (*models.ImportPreview, []models.ErrorResponse, error)
+	ApplyImportPreview(token string) (*models.ImportResult, error)
 	GetInvalidContactsForCorrection() ([]models.RowData, error)
+	ExportErrorWorkbook(w io.Writer) error
+	WriteImportTemplate(w io.Writer) error
+	TestRules(request *models.RuleTestRequest) (*models.RuleTestResult, []models.ErrorResponse, error)
+	TransformContactos(request *models.TransformRequest, filter SearchOptions, dryRun bool) (*models.TransformResult, []models.ErrorResponse, error)
+	UndoTransform(id string) (*models.TransformUndoResult, error)
+	
+	// 🆕 TRABAJOS EN SEGUNDO PLANO
+	StartImportJob(fileName string, data []byte, mode string) (*models.Job, []models.ErrorResponse, error)
+	StartReloadJob() (*models.Job, error)
+	GetJob(id string) (*models.Job, error)
+	ListJobs() []models.Job
+	CancelJob(id string) (*models.Job, error)
+	GetJobResult(id string) (interface{}, error)
+	
+	// 🆕 STREAM DE CAMBIOS
+	SubscribeEvents(lastEventID uint64, resume bool) *EventSubscription
+	
+	// 🆕 WEBHOOKS
+	ListWebhooks() ([]models.WebhookSubscription, error)
+	CreateWebhook(request *models.WebhookRequest) (*models.WebhookSubscription, []models.ErrorResponse, error)
+	DeleteWebhook(id string) error
+	ListWebhookDeliveries(status string) ([]models.WebhookDelivery, error)
+	RetryWebhookDelivery(id string) (*models.WebhookDelivery, error)
 	
 	// 🆕 NUEVOS MÉTODOS PARA PAGINACIÓN
 	GetContactosPaginated(page, size int, search string) (*PaginatedResult, error)
 	SearchContactosPaginated(searchTerm string, page, size int) (*PaginatedResult, error)
+	SearchPaginated(opts SearchOptions, page, size int) (*PaginatedResult, error)
+	SearchCursor(opts SearchOptions, cursor string, size int) (*PaginatedResult, error)
 	GetContactosCount() (int, error)
 	
+	// 🆕 EXPORTACIÓN
+	ExportContactos(opts SearchOptions, fn func(models.Contacto) error) error
+	
+	// 🆕 ORDENAMIENTO
+	SortContactos(contactos []models.Contacto, spec SortSpec) []models.Contacto
+	
+	// 🆕 AUTOCOMPLETADO
+	Suggest(prefix string, limit int) ([]Suggestion, error)
+	
 	// 🆕 MÉTODO PARA STATS
-	GetContactoStats() (map[string]interface{}, error)
+	GetContactoStats() (*models.ContactoStats, error)
 }
 
 // ContactoService implementa la lógica de negocio para contactos
 type ContactoService struct {
 	repo      repositories.ContactoRepositoryInterface
 	validator *validators.ContactoValidator
+	stats     *statsTracker
+	
+	// Historial de cargas para comparar recargas: el reporte de la carga vigente,
+	// armado al cargar, pasa a ser el previo con la siguiente recarga o importación.
+	// loadReport se usa con loadMu tomado; previousReport y lastDiff se leen sin él
+	// para no esperar a una recarga en curso
+	loadReport     *models.ExcelValidationReport
+	previousReport atomic.Pointer[models.ExcelValidationReport]
+	lastDiff       atomic.Pointer[models.ValidationDiff]
+	
+	// Vistas previas de importación pendientes de aplicar
+	previews *previewStore
+	
+	// Entradas para deshacer transformaciones masivas
+	undos *undoStore
+	
+	// Importaciones y recargas en segundo plano
+	jobs *jobManager
+	
+	// Stream de cambios para clientes suscritos
+	events *eventBroker
+	// webhooks nil hasta llamar ConfigureWebhooks
+	webhooks *webhookDispatcher
+	
+	// Índice de la búsqueda difusa, reconstruido en segundo plano cuando cambia version
+	fuzzy *backgroundIndex[fuzzyIndex]
+	
+	// Órdenes y resultados ordenados, descartados cuando cambia version
+	sorting sortCache
+	
+	// Conteos de facetas por consulta, descartados cuando cambia version
+	facets facetCache
+	
+	// Índice de prefijos del autocompletado, reconstruido en segundo plano cuando cambia version
+	suggest *backgroundIndex[suggestIndex]
+	
+	// version se incrementa con cada mutación; invalida vistas previas e índices
+	version atomic.Uint64
+	// loadMu serializa cargas, importaciones y mutaciones
+	loadMu sync.Mutex
 }
 
 // NewContactoService crea una nueva instancia del servicio
 func NewContactoService(repo repositories.ContactoRepositoryInterface) *ContactoService {
-	return &ContactoService{
+	service := &ContactoService{
 		repo:      repo,
 		validator: validators.NewContactoValidator(),
+		stats:     newStatsTracker(),
+		previews:  newPreviewStore(),
+		undos:     newUndoStore(),
+		jobs:      newJobManager(),
+		events:    newEventBroker(),
+		fuzzy:     newBackgroundIndex(buildFuzzyIndex),
+		suggest:   newBackgroundIndex(buildSuggestIndex),
+	}
+	
+	// Estadísticas iniciales a partir de los datos cargados
+	if contactos, err := repo.GetAll(); err == nil {
+		service.stats.reset(contactos)
+		service.loadReport = buildValidationReport(len(contactos), repo.GetLoadErrors(), repo.GetInvalidRowsData(), defaultTopErrors)
+	}
+	
+	return service
+}
+
+// ConfigureValidator reemplaza las reglas de validación; si son inválidas conserva las actuales.
+// El repositorio las usa desde su siguiente carga y las estadísticas se recalculan con ellas.
+func (s *ContactoService) ConfigureValidator(config validators.ValidatorConfig) error {
+	validator, err := validators.NewContactoValidatorWithConfig(config)
+	if err != nil {
+		return err
+	}
+
+	s.loadMu.Lock()
+	defer s.loadMu.Unlock()
+
+	s.validator = validator
+	if loader, ok := s.repo.(repositories.ValidatingLoader); ok {
+		loader.SetValidator(validator)
 	}
+	if contactos, err := s.repo.GetAll(); err == nil {
+		s.stats.setTelefonoDigitos(validator.Config().TelefonoDigitos, contactos)
+	}
+	return nil
 }
 
 // GetAllContactos obtiene todos los contactos
@@ -71,6 +191,9 @@ func (s *ContactoService) GetContactoByID(claveCliente int) (*models.Contacto, e
 
 // CreateContacto crea un nuevo contacto
 func (s *ContactoService) CreateContacto(request *models.ContactoRequest) (*models.Contacto, []models.ErrorResponse, error) {
+	s.loadMu.Lock()
+	defer s.loadMu.Unlock()
+	
 	// Convertir request a modelo
 	contacto := request.ToContacto()
 
@@ -96,12 +219,18 @@ func (s *ContactoService) CreateContacto(request *models.ContactoRequest) (*mode
 	if err := s.repo.Create(contacto); err != nil {
 		return nil, nil, fmt.Errorf("error creando contacto: %w", err)
 	}
+	s.stats.apply(nil, contacto)
+	s.dataChanged()
+	s.events.publish(models.EventCreated, EventSourceAPI, []int{contacto.ClaveCliente})
 
 	return contacto, nil, nil
 }
 
 // UpdateContacto actualiza un contacto existente
 func (s *ContactoService) UpdateContacto(claveCliente int, request *models.ContactoRequest) (*models.Contacto, []models.ErrorResponse, error) {
+	s.loadMu.Lock()
+	defer s.loadMu.Unlock()
+	
 	// Validar clave cliente
 	if claveCliente <= 0 {
 		return nil, []models.ErrorResponse{{
@@ -111,10 +240,11 @@ func (s *ContactoService) UpdateContacto(claveCliente int, request *models.Conta
 	}
 
 	// Verificar que el contacto exista
-	_, err := s.repo.GetByID(claveCliente)
+	existente, err := s.repo.GetByID(claveCliente)
 	if err != nil {
 		return nil, nil, fmt.Errorf("contacto no encontrado: %w", err)
 	}
+	anterior := *existente
 
 	// Convertir request a modelo
 	contacto := request.ToContacto()
@@ -132,26 +262,36 @@ func (s *ContactoService) UpdateContacto(claveCliente int, request *models.Conta
 	if err := s.repo.Update(contacto); err != nil {
 		return nil, nil, fmt.Errorf("error actualizando contacto: %w", err)
 	}
+	s.stats.apply(&anterior, contacto)
+	s.dataChanged()
+	s.events.publish(models.EventUpdated, EventSourceAPI, []int{claveCliente})
 
 	return contacto, nil, nil
 }
 
 // DeleteContacto elimina un contacto
 func (s *ContactoService) DeleteContacto(claveCliente int) error {
+	s.loadMu.Lock()
+	defer s.loadMu.Unlock()
+	
 	if claveCliente <= 0 {
 		return fmt.Errorf("clave cliente inválida: %d", claveCliente)
 	}
 
 	// Verificar que el contacto exista
-	_, err := s.repo.GetByID(claveCliente)
+	existente, err := s.repo.GetByID(claveCliente)
 	if err != nil {
 		return fmt.Errorf("contacto no encontrado: %w", err)
 	}
+	anterior := *existente
 
 	// Eliminar contacto
 	if err := s.repo.Delete(claveCliente); err != nil {
 		return fmt.Errorf("error eliminando contacto: %w", err)
 	}
+	s.stats.apply(&anterior, nil)
+	s.dataChanged()
+	s.events.publish(models.EventDeleted, EventSourceAPI, []int{claveCliente})
 
 	return nil
 }
@@ -186,27 +326,20 @@ func (s *ContactoService) SearchContactos(criteria *models.ContactoDTO) ([]model
 
 // GetContactosPaginated obtiene contactos con paginación
 func (s *ContactoService) GetContactosPaginated(page, size int, search string) (*PaginatedResult, error) {
-	// Obtener todos los contactos
-	allContactos, err := s.repo.GetAll()
-	if err != nil {
-		return nil, fmt.Errorf("error obteniendo contactos: %w", err)
-	}
-	
+	return s.SearchPaginated(SearchOptions{Query: search}, page, size)
+}
+
+// SearchPaginated filtra con los criterios indicados y pagina; en modo difuso
+// los resultados vienen ordenados por similitud con su puntuación en Hits
+func (s *ContactoService) SearchPaginated(opts SearchOptions, page, size int) (*PaginatedResult, error) {
 	// Filtrar si hay término de búsqueda
-	var filteredContactos []models.Contacto
-	if search != "" {
-		searchLower := strings.ToLower(search)
-		for _, contacto := range allContactos {
-			if strings.Contains(strings.ToLower(contacto.Nombre), searchLower) ||
-			   strings.Contains(strings.ToLower(contacto.Correo), searchLower) ||
-			   strings.Contains(contacto.TelefonoContacto, search) ||
-			   strings.Contains(fmt.Sprintf("%d", contacto.ClaveCliente), search) {
-				filteredContactos = append(filteredContactos, contacto)
-			}
-		}
-	} else {
-		filteredContactos = allContactos
+	version := s.version.Load()
+	result, err := s.filterContactos(opts)
+	if err != nil {
+		return nil, err
 	}
+	filteredContactos := result.contactos
+	facets := s.facetsFor(version, opts, result)
 	
 	total := len(filteredContactos)
 	totalPages := (total + size - 1) / size // Ceil division
@@ -225,6 +358,8 @@ func (s *ContactoService) GetContactosPaginated(page, size int, search string) (
 			TotalPages: totalPages,
 			HasNext:    false,
 			HasPrev:    page > 0,
+			Partial:    result.partial,
+			Facets:     facets,
 		}, nil
 	}
 	
@@ -237,12 +372,15 @@ func (s *ContactoService) GetContactosPaginated(page, size int, search string) (
 	
 	return &PaginatedResult{
 		Data:       pageData,
+		Hits:       result.buildHits(pageData, startIndex),
 		Page:       page,
 		Size:       size,
 		Total:      total,
 		TotalPages: totalPages,
 		HasNext:    page < totalPages-1,
 		HasPrev:    page > 0,
+		Partial:    result.partial,
+		Facets:     facets,
 	}, nil
 }
 
@@ -261,136 +399,149 @@ func (s *ContactoService) GetContactosCount() (int, error) {
 	return len(contactos), nil
 }
 
-// 🆕 GetContactoStats obtiene estadísticas de contactos
-func (s *ContactoService) GetContactoStats() (map[string]interface{}, error) {
+// 🆕 GetContactoStats obtiene estadísticas de contactos (mantenidas incrementalmente)
+func (s *ContactoService) GetContactoStats() (*models.ContactoStats, error) {
+	totalErrores := len(s.repo.GetLoadErrors())
+	totalInvalidos := len(s.repo.GetInvalidRowsData())
+	
+	return s.stats.snapshot(totalErrores, totalInvalidos), nil
+}
+
+// MÉTODOS EXISTENTES CONTINUACIÓN...
+
+// GetExcelValidationReport obtiene el reporte de validación con su resumen
+func (s *ContactoService) GetExcelValidationReport(topErrors int) (*models.ExcelValidationReport, error) {
+	loadErrors := s.repo.GetLoadErrors()
 	contactos, err := s.repo.GetAll()
 	if err != nil {
-		return nil, fmt.Errorf("error obteniendo contactos para stats: %w", err)
+		return nil, fmt.Errorf("error obteniendo contactos: %w", err)
 	}
-	
-	// Obtener datos de errores
-	loadErrors := s.repo.GetLoadErrors()
-	var invalidRowsData []models.RowData
-	if repo, ok := s.repo.(*repositories.ContactoRepository); ok {
-		invalidRowsData = repo.GetInvalidRowsData()
-	}
-	
-	// Calcular estadísticas
-	totalContactos := len(contactos)
-	totalErrores := len(loadErrors)
-	totalInvalidos := len(invalidRowsData)
-	
-	// Estadísticas de dominios de correo
-	dominios := make(map[string]int)
-	for _, contacto := range contactos {
-		if contacto.Correo != "" && strings.Contains(contacto.Correo, "@") {
-			parts := strings.Split(contacto.Correo, "@")
-			if len(parts) == 2 {
-				dominio := strings.ToLower(parts[1])
-				dominios[dominio]++
+
+	invalidRowsData := s.repo.GetInvalidRowsData()
+
+	return buildValidationReport(len(contactos), loadErrors, invalidRowsData, topErrors), nil
+}
+
+// ReloadExcel recarga el Excel y calcula el diff contra el estado anterior
+func (s *ContactoService) ReloadExcel() (*models.ExcelValidationReport, error) {
+	return s.reloadExcel(context.Background(), nil)
+}
+
+// reloadExcel recarga con cancelación y avance si el repositorio lo soporta; se puede cancelar
+// al abrir el libro y al validar filas, y si se cancela mientras espera a otra carga no recarga.
+// Con un StagedReloader el archivo se procesa fuera de loadMu, de modo que altas, ediciones,
+// importaciones y diffs no esperan a la lectura: el lock solo cubre el cambio de datos.
+func (s *ContactoService) reloadExcel(ctx context.Context, progress repositories.ProgressFunc) (*models.ExcelValidationReport, error) {
+	staged, ok := s.repo.(repositories.StagedReloader)
+	if !ok {
+		s.loadMu.Lock()
+		defer s.loadMu.Unlock()
+		return s.reloadLocked(ctx, func() ([]models.RowError, []models.RowData, error) {
+			if reloader, ok := s.repo.(repositories.ProgressReloader); ok {
+				return reloader.ReloadExcelContext(ctx, progress)
 			}
-		}
-	}
-	
-	// Top 5 dominios más comunes
-	type DominioCount struct {
-		Dominio string `json:"dominio"`
-		Count   int    `json:"count"`
-	}
-	
-	var topDominios []DominioCount
-	for dominio, count := range dominios {
-		topDominios = append(topDominios, DominioCount{
-			Dominio: dominio,
-			Count:   count,
+			return s.repo.ReloadExcel()
 		})
 	}
 	
-	// Ordenar por count (simple bubble sort para los primeros 5)
-	for i := 0; i < len(topDominios)-1 && i < 5; i++ {
-		for j := i + 1; j < len(topDominios); j++ {
-			if topDominios[j].Count > topDominios[i].Count {
-				topDominios[i], topDominios[j] = topDominios[j], topDominios[i]
+	apply := func(result *repositories.ParseResult) func() ([]models.RowError, []models.RowData, error) {
+		return func() ([]models.RowError, []models.RowData, error) {
+			if progress != nil {
+				progress(repositories.ParseProgress{Phase: JobPhaseApplying})
 			}
+			staged.ApplyReload(result)
+			return result.LoadErrors, result.InvalidRowsData, nil
 		}
 	}
 	
-	// Tomar solo los primeros 5
-	if len(topDominios) > 5 {
-		topDominios = topDominios[:5]
+	// Una mutación durante el procesamiento reescribe el archivo y lo leído puede ser anterior
+	// a ella: en ese caso se vuelve a procesar
+	for attempt := 1; attempt < maxReloadAttempts; attempt++ {
+		version := s.version.Load()
+		result, err := staged.ParseExcelContext(ctx, progress)
+		if err != nil {
+			return nil, reloadError(ctx, err)
+		}
+		
+		s.loadMu.Lock()
+		if s.version.Load() == version {
+			defer s.loadMu.Unlock()
+			return s.reloadLocked(ctx, apply(result))
+		}
+		s.loadMu.Unlock()
 	}
 	
-	return map[string]interface{}{
-		"totalContactos":   totalContactos,
-		"totalErrores":     totalErrores,
-		"totalInvalidos":   totalInvalidos,
-		"totalDominios":    len(dominios),
-		"topDominios":      topDominios,
-		"porcentajeValidos": func() float64 {
-			if totalContactos+totalInvalidos == 0 {
-				return 0
-			}
-			return float64(totalContactos) / float64(totalContactos+totalInvalidos) * 100
-		}(),
-		"timestamp": time.Now().Format("2006-01-02 15:04:05"),
-	}, nil
+	// Último intento con el lock tomado: nadie puede cambiar el archivo mientras se lee
+	s.loadMu.Lock()
+	defer s.loadMu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	result, err := staged.ParseExcelContext(ctx, progress)
+	if err != nil {
+		return nil, reloadError(ctx, err)
+	}
+	return s.reloadLocked(ctx, apply(result))
 }
 
-// MÉTODOS EXISTENTES CONTINUACIÓN...
+// reloadError distingue la cancelación de un error al procesar el archivo
+func reloadError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return err
+	}
+	return fmt.Errorf("error recargando Excel: %w", err)
+}
 
-func (s *ContactoService) GetExcelValidationReport() (*models.ExcelValidationReport, error) {
-	loadErrors := s.repo.GetLoadErrors()
-	contactos, err := s.repo.GetAll()
+// reloadLocked aplica una recarga con load y calcula el diff contra el estado anterior
+// (requiere loadMu tomado)
+func (s *ContactoService) reloadLocked(ctx context.Context, load func() ([]models.RowError, []models.RowData, error)) (*models.ExcelValidationReport, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	// Conservar el estado previo a la recarga
+	previo, err := s.captureSnapshot()
 	if err != nil {
-		return nil, fmt.Errorf("error obteniendo contactos: %w", err)
+		return nil, fmt.Errorf("error obteniendo contactos antes de recargar: %w", err)
 	}
-
-	var invalidRowsData []models.RowData
-	if repo, ok := s.repo.(*repositories.ContactoRepository); ok {
-		invalidRowsData = repo.GetInvalidRowsData()
+	
+	loadErrors, invalidRowsData, err := load()
+	if err != nil {
+		return nil, reloadError(ctx, err)
 	}
-
-	totalRows := len(contactos) + len(invalidRowsData)
-	validRows := len(contactos)
-	invalidRows := len(invalidRowsData)
-
-	return &models.ExcelValidationReport{
-		TotalRows:       totalRows,
-		ValidRows:       validRows,
-		InvalidRows:     invalidRows,
-		Errors:          loadErrors,
-		InvalidRowsData: invalidRowsData,
-		LoadTimestamp:   time.Now().Format("2006-01-02 15:04:05"),
-	}, nil
+	
+	actual, err := s.captureSnapshot()
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo contactos después de recargar: %w", err)
+	}
+	s.stats.reset(actual.contactos)
+	s.dataChanged()
+	
+	report := *s.recordLoad(previo, actual, loadErrors, invalidRowsData)
+	diff := diffSnapshots(previo, actual)
+	s.lastDiff.Store(diff)
+	s.publishDiff(EventSourceReload, diff)
+	
+	report.Diff = diff
+	return &report, nil
 }
 
-func (s *ContactoService) ReloadExcel() (*models.ExcelValidationReport, error) {
-	if repo, ok := s.repo.(*repositories.ContactoRepository); ok {
-		loadErrors, invalidRowsData, err := repo.ReloadExcel()
-		if err != nil {
-			return nil, fmt.Errorf("error recargando Excel: %w", err)
-		}
-
-		contactos, err := s.repo.GetAll()
-		if err != nil {
-			return nil, fmt.Errorf("error obteniendo contactos después de recargar: %w", err)
-		}
-
-		totalRows := len(contactos) + len(invalidRowsData)
-		validRows := len(contactos)
-		invalidRows := len(invalidRowsData)
-
-		return &models.ExcelValidationReport{
-			TotalRows:       totalRows,
-			ValidRows:       validRows,
-			InvalidRows:     invalidRows,
-			Errors:          loadErrors,
-			InvalidRowsData: invalidRowsData,
-			LoadTimestamp:   time.Now().Format("2006-01-02 15:04:05"),
-		}, nil
+// GetValidationDiff retorna los cambios de la última recarga o importación
+func (s *ContactoService) GetValidationDiff() (*models.ValidationDiff, error) {
+	diff := s.lastDiff.Load()
+	if diff == nil {
+		return nil, fmt.Errorf("aún no hay una recarga con la cual comparar")
 	}
+	return diff, nil
+}
 
-	return nil, fmt.Errorf("recarga de Excel no disponible")
+// GetPreviousValidationReport retorna el reporte de validación previo a la última recarga o importación
+func (s *ContactoService) GetPreviousValidationReport() (*models.ExcelValidationReport, error) {
+	report := s.previousReport.Load()
+	if report == nil {
+		return nil, fmt.Errorf("aún no hay una recarga con la cual comparar")
+	}
+	return report, nil
 }
 
 // ✅ MÉTODO CORREGIDO PARA INVALID DATA
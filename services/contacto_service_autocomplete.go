@@ -0,0 +1,143 @@
+// services/contacto_service_autocomplete.go
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"contactos-api/models"
+	"contactos-api/querylang"
+	"contactos-api/utils"
+)
+
+const (
+	// DefaultSuggestLimit sugerencias por defecto
+	DefaultSuggestLimit = 10
+	// MaxSuggestLimit sugerencias máximas por petición
+	MaxSuggestLimit = 50
+	// MaxSuggestPrefix longitud máxima del prefijo en bytes
+	MaxSuggestPrefix = 100
+)
+
+// Suggestion contacto sugerido para un prefijo
+type Suggestion struct {
+	ClaveCliente int    `json:"claveCliente"`
+	Nombre       string `json:"nombre"`
+	Correo       string `json:"correo"`
+	Field        string `json:"field"` // Campo que coincide con el prefijo
+	Exact        bool   `json:"exact"` // El valor completo empieza con el prefijo (no solo una palabra)
+}
+
+// suggestEntry término normalizado que apunta a un contacto del índice
+type suggestEntry struct {
+	term  string
+	pos   int32
+	field querylang.Field
+}
+
+// suggestIndex términos ordenados para búsqueda por prefijo con búsqueda binaria.
+// exact contiene los valores completos (nombre, correo, clave) y words las demás
+// palabras del nombre; las coincidencias de exact se sugieren primero.
+type suggestIndex struct {
+	version   uint64
+	contactos []models.Contacto
+	exact     []suggestEntry
+	words     []suggestEntry
+}
+
+// Suggest sugiere contactos cuyo nombre, correo o clave empiezan con el prefijo (sin acentos
+// ni mayúsculas), o alguna palabra del nombre; primero los que coinciden desde el inicio
+func (s *ContactoService) Suggest(prefix string, limit int) ([]Suggestion, error) {
+	version := s.version.Load()
+	allContactos, err := s.repo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo contactos: %w", err)
+	}
+
+	return s.suggest.get(version, allContactos).search(utils.NormalizeText(prefix), limit), nil
+}
+
+// buildSuggestIndex normaliza y ordena los términos de todos los contactos
+func buildSuggestIndex(version uint64, contactos []models.Contacto) *suggestIndex {
+	index := &suggestIndex{
+		version:   version,
+		contactos: contactos,
+		exact:     make([]suggestEntry, 0, len(contactos)*3),
+		words:     make([]suggestEntry, 0, len(contactos)*2),
+	}
+
+	for i, contacto := range contactos {
+		pos := int32(i)
+		nombre := utils.NormalizeText(contacto.Nombre)
+		index.exact = append(index.exact,
+			suggestEntry{term: nombre, pos: pos, field: querylang.FieldNombre},
+			suggestEntry{term: utils.NormalizeText(contacto.Correo), pos: pos, field: querylang.FieldCorreo},
+			suggestEntry{term: strconv.Itoa(contacto.ClaveCliente), pos: pos, field: querylang.FieldClave},
+		)
+
+		// Palabras siguientes del nombre: "gar" sugiere "Ana García" después de "García López"
+		for start := strings.IndexByte(nombre, ' '); start >= 0; {
+			rest := nombre[start+1:]
+			index.words = append(index.words, suggestEntry{term: rest, pos: pos, field: querylang.FieldNombre})
+			next := strings.IndexByte(rest, ' ')
+			if next < 0 {
+				break
+			}
+			start += next + 1
+		}
+	}
+
+	sortSuggestEntries(index.exact)
+	sortSuggestEntries(index.words)
+	return index
+}
+
+func sortSuggestEntries(entries []suggestEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].term != entries[j].term {
+			return entries[i].term < entries[j].term
+		}
+		return entries[i].pos < entries[j].pos
+	})
+}
+
+// search recorre solo el rango de cada lista que empieza con el prefijo, hasta juntar limit contactos
+func (idx *suggestIndex) search(prefix string, limit int) []Suggestion {
+	suggestions := []Suggestion{}
+	if prefix == "" {
+		return suggestions
+	}
+
+	seen := make(map[int32]bool, limit)
+	groups := []struct {
+		entries []suggestEntry
+		exact   bool
+	}{{idx.exact, true}, {idx.words, false}}
+
+	for _, group := range groups {
+		entries := group.entries
+		i := sort.Search(len(entries), func(i int) bool { return entries[i].term >= prefix })
+		for ; i < len(entries) && len(suggestions) < limit; i++ {
+			entry := entries[i]
+			if !strings.HasPrefix(entry.term, prefix) {
+				break
+			}
+			if seen[entry.pos] {
+				continue
+			}
+			seen[entry.pos] = true
+
+			contacto := idx.contactos[entry.pos]
+			suggestions = append(suggestions, Suggestion{
+				ClaveCliente: contacto.ClaveCliente,
+				Nombre:       contacto.Nombre,
+				Correo:       contacto.Correo,
+				Field:        entry.field.String(),
+				Exact:        group.exact,
+			})
+		}
+	}
+	return suggestions
+}
@@ -0,0 +1,191 @@
+// services/contacto_service_cursor.go
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+
+	"contactos-api/models"
+	"contactos-api/querylang"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+var (
+	// ErrInvalidCursor el cursor no se puede leer o pertenece a otra consulta u orden
+	ErrInvalidCursor = errors.New("cursor inválido")
+)
+
+// pageCursor posición opaca dentro de un listado ordenado: los valores de orden y la clave
+// del contacto ancla. Al no depender de posiciones, altas y bajas entre peticiones no
+// duplican ni saltan filas.
+type pageCursor struct {
+	Sort   string   `json:"s"`           // Criterio de orden con el que se generó
+	Filter string   `json:"f"`           // Huella de los criterios de búsqueda
+	Values []string `json:"v"`           // Valores de los campos de orden del ancla
+	Clave  int      `json:"c"`           // ClaveCliente del ancla (desempate)
+	Before bool     `json:"b,omitempty"` // true: página anterior al ancla; false: siguiente
+}
+
+// cursorSort orden del listado por cursor: el criterio pedido o, sin criterio, ClaveCliente
+func cursorSort(spec SortSpec) SortSpec {
+	if len(spec) == 0 {
+		return SortSpec{{Field: querylang.FieldClave}}
+	}
+	return spec
+}
+
+// filterFingerprint huella de los criterios de búsqueda para ligar un cursor a su consulta
+func filterFingerprint(opts SearchOptions) string {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s|%s|%g|%d", opts.Mode, opts.Query, opts.MinScore, opts.Field)
+	return strconv.FormatUint(h.Sum64(), 36)
+}
+
+func encodeCursor(cursor pageCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(token string, spec SortSpec, opts SearchOptions) (*pageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor pageCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.Sort != spec.String() || cursor.Filter != filterFingerprint(opts) {
+		return nil, fmt.Errorf("%w: pertenece a otra búsqueda u orden", ErrInvalidCursor)
+	}
+	if len(cursor.Values) != len(spec) {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// newCursor cursor anclado en un contacto del listado
+func newCursor(contacto models.Contacto, spec SortSpec, opts SearchOptions, before bool) string {
+	values := make([]string, len(spec))
+	for i, field := range spec {
+		values[i] = contactoFieldValue(contacto, field.Field)
+	}
+	return encodeCursor(pageCursor{
+		Sort:   spec.String(),
+		Filter: filterFingerprint(opts),
+		Values: values,
+		Clave:  contacto.ClaveCliente,
+		Before: before,
+	})
+}
+
+// turn el mismo ancla en la dirección indicada
+func (c pageCursor) turn(before bool) string {
+	c.Before = before
+	return encodeCursor(c)
+}
+
+// compareToCursor compara un contacto con el ancla usando el mismo orden que buildSortIndex
+func compareToCursor(collator *collate.Collator, spec SortSpec, contacto models.Contacto, cursor *pageCursor) int {
+	for i, field := range spec {
+		var cmp int
+		if field.Field == querylang.FieldClave {
+			clave, _ := strconv.Atoi(cursor.Values[i])
+			cmp = compareInts(contacto.ClaveCliente, clave)
+		} else {
+			cmp = collator.CompareString(contactoFieldValue(contacto, field.Field), cursor.Values[i])
+		}
+		if field.Desc {
+			cmp = -cmp
+		}
+		if cmp != 0 {
+			return cmp
+		}
+	}
+	return compareInts(contacto.ClaveCliente, cursor.Clave)
+}
+
+// SearchCursor página de resultados por cursor (keyset). Sin cursor retorna la primera página;
+// Next y Prev del resultado continúan hacia adelante o atrás desde sus extremos.
+func (s *ContactoService) SearchCursor(opts SearchOptions, token string, size int) (*PaginatedResult, error) {
+	opts.Sort = cursorSort(opts.Sort)
+
+	var cursor *pageCursor
+	if token != "" {
+		decoded, err := decodeCursor(token, opts.Sort, opts)
+		if err != nil {
+			return nil, err
+		}
+		cursor = decoded
+	}
+
+	version := s.version.Load()
+	result, err := s.filterContactos(opts)
+	if err != nil {
+		return nil, err
+	}
+	contactos := result.contactos
+	total := len(contactos)
+
+	start, end := 0, size
+	if cursor != nil {
+		collator := collate.New(language.Spanish)
+		if cursor.Before {
+			end = sort.Search(total, func(i int) bool {
+				return compareToCursor(collator, opts.Sort, contactos[i], cursor) >= 0
+			})
+			start = end - size
+		} else {
+			start = sort.Search(total, func(i int) bool {
+				return compareToCursor(collator, opts.Sort, contactos[i], cursor) > 0
+			})
+			end = start + size
+		}
+	}
+	if start < 0 {
+		start = 0
+	}
+	if end > total {
+		end = total
+	}
+	if end < start {
+		end = start
+	}
+
+	pageData := contactos[start:end]
+	page := &PaginatedResult{
+		Data:       pageData,
+		Hits:       result.buildHits(pageData, start),
+		Page:       start / size,
+		Size:       size,
+		Total:      total,
+		TotalPages: (total + size - 1) / size,
+		HasNext:    end < total,
+		HasPrev:    start > 0,
+		Partial:    result.partial,
+		Facets:     s.facetsFor(version, opts, result),
+	}
+	// Con una página vacía (el ancla quedó fuera del listado) se continúa desde el mismo ancla
+	if page.HasNext {
+		if end > start {
+			page.Next = newCursor(contactos[end-1], opts.Sort, opts, false)
+		} else {
+			page.Next = cursor.turn(false)
+		}
+	}
+	if page.HasPrev {
+		if end > start {
+			page.Prev = newCursor(contactos[start], opts.Sort, opts, true)
+		} else {
+			page.Prev = cursor.turn(true)
+		}
+	}
+	return page, nil
+}
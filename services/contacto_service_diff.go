@@ -0,0 +1,154 @@
+// services/contacto_service_diff.go
+package services
+
+import (
+	"strconv"
+	"time"
+
+	"contactos-api/models"
+)
+
+// loadSnapshot conserva el estado de una carga para compararlo con la siguiente
+type loadSnapshot struct {
+	timestamp string
+	contactos []models.Contacto
+	porClave  map[int]models.Contacto
+	invalidos []models.RowData
+	porFila   map[string]models.RowData
+}
+
+// captureSnapshot copia el estado actual del repositorio
+func (s *ContactoService) captureSnapshot() (*loadSnapshot, error) {
+	contactos, err := s.repo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &loadSnapshot{
+		timestamp: time.Now().Format("2006-01-02 15:04:05"),
+		contactos: append([]models.Contacto(nil), contactos...),
+		invalidos: append([]models.RowData(nil), s.repo.GetInvalidRowsData()...),
+	}
+
+	snapshot.porClave = make(map[int]models.Contacto, len(snapshot.contactos))
+	for _, contacto := range snapshot.contactos {
+		snapshot.porClave[contacto.ClaveCliente] = contacto
+	}
+
+	snapshot.porFila = make(map[string]models.RowData, len(snapshot.invalidos))
+	for _, rowData := range snapshot.invalidos {
+		snapshot.porFila[rowIdentity(rowData)] = rowData
+	}
+
+	return snapshot, nil
+}
+
+// recordLoad guarda el reporte de la carga recién hecha (recarga o importación de cualquier
+// modo) y pasa el de la carga anterior a previousReport; los snapshots toman la hora de su
+// carga (requiere loadMu tomado)
+func (s *ContactoService) recordLoad(previo, actual *loadSnapshot, loadErrors []models.RowError, invalidRowsData []models.RowData) *models.ExcelValidationReport {
+	report := buildValidationReport(len(actual.contactos), loadErrors, invalidRowsData, defaultTopErrors)
+	if s.loadReport != nil {
+		previo.timestamp = s.loadReport.LoadTimestamp
+	}
+	actual.timestamp = report.LoadTimestamp
+
+	s.previousReport.Store(s.loadReport)
+	s.loadReport = report
+	return report
+}
+
+// diffSnapshots calcula los cambios entre dos cargas
+func diffSnapshots(previo, actual *loadSnapshot) *models.ValidationDiff {
+	diff := &models.ValidationDiff{
+		PreviousLoadTimestamp: previo.timestamp,
+		CurrentLoadTimestamp:  actual.timestamp,
+		NewlyInvalidRows:      []models.RowData{},
+		FixedRows:             []models.RowData{},
+		AddedContacts:         []models.Contacto{},
+		RemovedContacts:       []models.Contacto{},
+		ChangedContacts:       []models.ContactoChange{},
+	}
+
+	// Filas que antes no eran inválidas
+	for _, rowData := range actual.invalidos {
+		if _, existia := previo.porFila[rowIdentity(rowData)]; !existia {
+			diff.NewlyInvalidRows = append(diff.NewlyInvalidRows, rowData)
+		}
+	}
+
+	// Filas inválidas que ahora cargan como contacto válido
+	for _, rowData := range previo.invalidos {
+		if _, sigue := actual.porFila[rowIdentity(rowData)]; sigue {
+			continue
+		}
+		if clave, err := strconv.Atoi(rowData.ClaveCliente); err == nil {
+			if _, valido := actual.porClave[clave]; valido {
+				diff.FixedRows = append(diff.FixedRows, rowData)
+			}
+		}
+	}
+
+	// Contactos agregados y modificados
+	for _, contacto := range actual.contactos {
+		anterior, existia := previo.porClave[contacto.ClaveCliente]
+		if !existia {
+			diff.AddedContacts = append(diff.AddedContacts, contacto)
+			continue
+		}
+		if cambios := diffContacto(anterior, contacto); len(cambios) > 0 {
+			diff.ChangedContacts = append(diff.ChangedContacts, models.ContactoChange{
+				ClaveCliente: contacto.ClaveCliente,
+				Before:       anterior,
+				After:        contacto,
+				Changes:      cambios,
+			})
+		}
+	}
+
+	// Contactos eliminados
+	for _, contacto := range previo.contactos {
+		if _, sigue := actual.porClave[contacto.ClaveCliente]; !sigue {
+			diff.RemovedContacts = append(diff.RemovedContacts, contacto)
+		}
+	}
+
+	diff.Counts = models.DiffCounts{
+		NewlyInvalid: len(diff.NewlyInvalidRows),
+		Fixed:        len(diff.FixedRows),
+		Added:        len(diff.AddedContacts),
+		Removed:      len(diff.RemovedContacts),
+		Changed:      len(diff.ChangedContacts),
+	}
+
+	return diff
+}
+
+// diffContacto compara campo a campo dos versiones de un contacto
+func diffContacto(antes, despues models.Contacto) []models.FieldChange {
+	var cambios []models.FieldChange
+
+	if antes.Nombre != despues.Nombre {
+		cambios = append(cambios, models.FieldChange{Field: "nombre", Before: antes.Nombre, After: despues.Nombre})
+	}
+	if antes.Correo != despues.Correo {
+		cambios = append(cambios, models.FieldChange{Field: "correo", Before: antes.Correo, After: despues.Correo})
+	}
+	if antes.TelefonoContacto != despues.TelefonoContacto {
+		cambios = append(cambios, models.FieldChange{
+			Field:  "telefonoContacto",
+			Before: antes.TelefonoContacto,
+			After:  despues.TelefonoContacto,
+		})
+	}
+
+	return cambios
+}
+
+// rowIdentity identifica una fila inválida entre cargas: por clave si existe, si no por número de fila
+func rowIdentity(rowData models.RowData) string {
+	if rowData.ClaveCliente != "" {
+		return "clave:" + rowData.ClaveCliente
+	}
+	return "fila:" + strconv.Itoa(rowData.Row)
+}
@@ -0,0 +1,89 @@
+// services/contacto_service_error_workbook.go
+package services
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"contactos-api/exporters"
+	"contactos-api/models"
+	"contactos-api/repositories"
+)
+
+// ExportErrorWorkbook escribe una copia del libro de la última carga con las celdas inválidas marcadas.
+// Válidas e inválidas quedan en el orden de sus filas originales, de modo que el archivo corregido
+// se puede volver a importar. El libro se reconstruye desde los datos actuales: los contactos
+// eliminados no aparecen y los agregados después de la carga van al final.
+func (s *ContactoService) ExportErrorWorkbook(w io.Writer) error {
+	s.loadMu.Lock()
+	snapshot, err := s.captureSnapshot()
+	loadErrors := append([]models.RowError(nil), s.repo.GetLoadErrors()...)
+	var sourceRows map[int]int
+	if tracker, ok := s.repo.(repositories.SourceRowTracker); ok {
+		sourceRows = tracker.SourceRows()
+	}
+	s.loadMu.Unlock()
+	if err != nil {
+		return fmt.Errorf("error obteniendo contactos: %w", err)
+	}
+
+	rows := rebuildWorkbookRows(snapshot.contactos, snapshot.invalidos, sourceRows)
+	rules := s.validator.Config()
+
+	sort.SliceStable(loadErrors, func(i, j int) bool {
+		return loadErrors[i].Row < loadErrors[j].Row
+	})
+	cellErrors := make([]exporters.WorkbookError, len(loadErrors))
+	for i, rowError := range loadErrors {
+		cellErrors[i] = exporters.WorkbookError{
+			SourceRow:  rowError.Row,
+			Column:     rowError.Column,
+			Field:      rowError.Field,
+			Value:      rowError.Value,
+			Message:    rowError.Error,
+			Suggestion: suggestFix(rowError, rules),
+		}
+	}
+
+	return exporters.WriteErrorWorkbook(w, rows, cellErrors)
+}
+
+// rebuildWorkbookRows ordena las filas por su número en el archivo cargado (la fila 1 es el
+// encabezado). Los contactos sin fila conocida (agregados después de la carga o sin sourceRows)
+// van al final en su orden actual.
+func rebuildWorkbookRows(contactos []models.Contacto, invalidos []models.RowData, sourceRows map[int]int) []exporters.WorkbookRow {
+	rows := make([]exporters.WorkbookRow, 0, len(contactos)+len(invalidos))
+	var agregados []exporters.WorkbookRow
+
+	for _, rowData := range invalidos {
+		_, errClave := strconv.Atoi(rowData.ClaveCliente)
+		rows = append(rows, exporters.WorkbookRow{
+			SourceRow: rowData.Row,
+			Values:    [4]string{rowData.ClaveCliente, rowData.Nombre, rowData.Correo, rowData.TelefonoContacto},
+			Numeric:   errClave == nil,
+		})
+	}
+
+	for _, contacto := range contactos {
+		row := exporters.WorkbookRow{
+			Values: [4]string{
+				strconv.Itoa(contacto.ClaveCliente), contacto.Nombre, contacto.Correo, contacto.TelefonoContacto,
+			},
+			Numeric: true,
+		}
+		fila, ok := sourceRows[contacto.ClaveCliente]
+		if !ok {
+			agregados = append(agregados, row)
+			continue
+		}
+		row.SourceRow = fila
+		rows = append(rows, row)
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].SourceRow < rows[j].SourceRow
+	})
+	return append(rows, agregados...)
+}
@@ -0,0 +1,275 @@
+// services/contacto_service_facets.go
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"contactos-api/models"
+	"contactos-api/querylang"
+	"contactos-api/repositories"
+	"contactos-api/validators"
+)
+
+// Facetas disponibles con facets=
+const (
+	FacetDomain     = "domain"     // Dominio del correo
+	FacetAreaCode   = "areaCode"   // Clave LADA del teléfono
+	FacetValidation = "validation" // Estado con las reglas de validación activas
+	FacetSource     = "source"     // Archivo (y hoja) del que se cargó o importó
+)
+
+// FacetSourceAPI origen de los contactos dados de alta por la API después de la última carga
+const FacetSourceAPI = "api"
+
+// Estados de la faceta de validación
+const (
+	FacetValid    = "valido"    // Contacto cargado que cumple las reglas activas
+	FacetInvalid  = "invalido"  // Contacto cargado que ya no cumple las reglas activas
+	FacetRejected = "rechazado" // Fila rechazada en la carga (no forma parte de los resultados)
+)
+
+const (
+	// maxFacetBuckets valores reportados por faceta; el resto se suma en Other
+	maxFacetBuckets = 20
+	// maxCachedFacets conteos guardados por consulta (se descartan al cambiar los datos)
+	maxCachedFacets = 16
+)
+
+// facetNames facetas en el orden de la documentación
+var facetNames = []string{FacetDomain, FacetAreaCode, FacetValidation, FacetSource}
+
+// FacetBucket cantidad de resultados con un mismo valor
+type FacetBucket struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// Facet conteos de una faceta sobre todos los resultados de la búsqueda
+type Facet struct {
+	Buckets []FacetBucket `json:"buckets"`           // De mayor a menor cantidad
+	Other   int           `json:"other,omitempty"`   // Resultados con valores fuera de los primeros maxFacetBuckets
+	Missing int           `json:"missing,omitempty"` // Resultados sin valor para la faceta
+}
+
+// ParseFacets interpreta "domain,areaCode"; "all" pide todas
+func ParseFacets(spec string) ([]string, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+	if strings.TrimSpace(spec) == "all" {
+		return facetNames, nil
+	}
+
+	var facets []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		if !isFacetName(name) {
+			return nil, fmt.Errorf("faceta '%s' desconocida. Use %s o all", name, strings.Join(facetNames, ", "))
+		}
+		if !seen[name] {
+			seen[name] = true
+			facets = append(facets, name)
+		}
+	}
+	return facets, nil
+}
+
+func isFacetName(name string) bool {
+	for _, facet := range facetNames {
+		if facet == name {
+			return true
+		}
+	}
+	return false
+}
+
+// facetEntry conteos de una consulta en una versión de datos y reglas
+type facetEntry struct {
+	version   uint64
+	validator *validators.ContactoValidator
+	key       string
+	facets    map[string]*Facet
+}
+
+// facetCache conteos recientes, para no recontar al pasar de página
+type facetCache struct {
+	entries []facetEntry
+	mu      sync.Mutex
+}
+
+// facetsFor cuenta las facetas pedidas sobre todos los contactos del resultado (no solo la página);
+// la faceta de validación suma además las filas rechazadas en la carga que cumplen los criterios
+func (s *ContactoService) facetsFor(version uint64, opts SearchOptions, result *searchResult) map[string]*Facet {
+	if len(opts.Facets) == 0 {
+		return nil
+	}
+
+	validator := s.validator
+	key := fmt.Sprintf("%s|%s|%g|%d|%s", opts.Mode, opts.Query, opts.MinScore, opts.Field, strings.Join(opts.Facets, ","))
+
+	s.facets.mu.Lock()
+	defer s.facets.mu.Unlock()
+
+	kept := s.facets.entries[:0]
+	for _, entry := range s.facets.entries {
+		if entry.version != version || entry.validator != validator {
+			continue // Datos o reglas viejos: descartar
+		}
+		if entry.key == key {
+			return entry.facets
+		}
+		kept = append(kept, entry)
+	}
+	s.facets.entries = kept
+
+	var sources map[int]string
+	if tracker, ok := s.repo.(repositories.SourceTracker); ok {
+		sources = tracker.Sources()
+	}
+
+	facets := countFacets(opts.Facets, result.contactos, validator, sources)
+	if validation, ok := facets[FacetValidation]; ok {
+		if rechazadas := matchInvalidRows(opts, s.repo.GetInvalidRowsData()); rechazadas > 0 {
+			validation.Buckets = append(validation.Buckets, FacetBucket{Value: FacetRejected, Count: rechazadas})
+			sortBuckets(validation.Buckets)
+		}
+	}
+
+	if len(s.facets.entries) >= maxCachedFacets {
+		s.facets.entries = s.facets.entries[1:]
+	}
+	s.facets.entries = append(s.facets.entries, facetEntry{version: version, validator: validator, key: key, facets: facets})
+	return facets
+}
+
+// countFacets recorre los contactos una vez acumulando todas las facetas pedidas; sources es el
+// origen por clave (nil si el repositorio no lo registra: la faceta source queda en Missing)
+func countFacets(names []string, contactos []models.Contacto, validator *validators.ContactoValidator, sources map[int]string) map[string]*Facet {
+	counts := make(map[string]map[string]int, len(names))
+	missing := make(map[string]int, len(names))
+	for _, name := range names {
+		counts[name] = make(map[string]int)
+	}
+	telefonoDigitos := validator.Config().TelefonoDigitos
+
+	for i := range contactos {
+		contacto := &contactos[i]
+		for _, name := range names {
+			var value string
+			switch name {
+			case FacetDomain:
+				value = dominioDeCorreo(contacto.Correo)
+			case FacetAreaCode:
+				// Mismas reglas que GetContactoStats; los teléfonos sin clave reconocible cuentan en Missing
+				if value = areaTelefonica(contacto.TelefonoContacto, telefonoDigitos); value == areaDesconocida {
+					value = ""
+				}
+			case FacetValidation:
+				value = FacetValid
+				if len(validator.ValidarContacto(contacto)) > 0 {
+					value = FacetInvalid
+				}
+			case FacetSource:
+				if sources != nil {
+					if value = sources[contacto.ClaveCliente]; value == "" {
+						value = FacetSourceAPI
+					}
+				}
+			}
+			if value == "" {
+				missing[name]++
+				continue
+			}
+			counts[name][value]++
+		}
+	}
+
+	facets := make(map[string]*Facet, len(names))
+	for _, name := range names {
+		facets[name] = topBuckets(counts[name], missing[name])
+	}
+	return facets
+}
+
+// topBuckets ordena por cantidad (y valor en empate) y conserva los primeros maxFacetBuckets
+func topBuckets(counts map[string]int, missing int) *Facet {
+	facet := &Facet{Buckets: make([]FacetBucket, 0, len(counts)), Missing: missing}
+	for value, count := range counts {
+		facet.Buckets = append(facet.Buckets, FacetBucket{Value: value, Count: count})
+	}
+	sortBuckets(facet.Buckets)
+
+	if len(facet.Buckets) > maxFacetBuckets {
+		for _, bucket := range facet.Buckets[maxFacetBuckets:] {
+			facet.Other += bucket.Count
+		}
+		facet.Buckets = facet.Buckets[:maxFacetBuckets]
+	}
+	return facet
+}
+
+func sortBuckets(buckets []FacetBucket) {
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].Count != buckets[j].Count {
+			return buckets[i].Count > buckets[j].Count
+		}
+		return buckets[i].Value < buckets[j].Value
+	})
+}
+
+// matchInvalidRows cuenta las filas rechazadas que cumplen los criterios de búsqueda, con los
+// valores tal como venían en el archivo; una clave no numérica solo coincide como texto
+func matchInvalidRows(opts SearchOptions, rows []models.RowData) int {
+	if opts.Query == "" {
+		return len(rows)
+	}
+
+	contactos := make([]models.Contacto, len(rows))
+	keys := make([]repositories.SearchKeys, len(rows))
+	for i, row := range rows {
+		clave, _ := strconv.Atoi(strings.TrimSpace(row.ClaveCliente))
+		contactos[i] = models.Contacto{ClaveCliente: clave, Nombre: row.Nombre, Correo: row.Correo, TelefonoContacto: row.TelefonoContacto}
+		keys[i] = repositories.NewSearchKeys(contactos[i])
+		keys[i].Clave = strings.TrimSpace(row.ClaveCliente)
+	}
+
+	count := 0
+	switch opts.Mode {
+	case SearchModeFuzzy:
+		minScore := opts.MinScore
+		if minScore <= 0 {
+			minScore = DefaultFuzzyMinScore
+		}
+		positions, _ := buildFuzzyIndex(0, contactos).search(opts.Query, minScore)
+		count = len(positions)
+	case SearchModeQuery:
+		expr, err := querylang.Parse(opts.Query)
+		if err != nil {
+			return 0
+		}
+		for i := range contactos {
+			if expr.Match(contactos[i], keys[i]) {
+				count++
+			}
+		}
+	case SearchModeRegex:
+		result, err := regexContactos(opts, contactos)
+		if err != nil {
+			return 0
+		}
+		count = len(result.contactos)
+	default:
+		terms := repositories.SearchTerms(opts.Query)
+		for i := range keys {
+			if keys[i].MatchesAll(terms) {
+				count++
+			}
+		}
+	}
+	return count
+}
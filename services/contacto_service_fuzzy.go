@@ -0,0 +1,210 @@
+// services/contacto_service_fuzzy.go
+package services
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"contactos-api/models"
+	"contactos-api/utils"
+)
+
+const (
+	// DefaultFuzzyMinScore similitud mínima por defecto para la búsqueda difusa
+	DefaultFuzzyMinScore = 0.6
+	// fuzzyPrefixScore similitud asignada cuando el término es prefijo de una palabra
+	fuzzyPrefixScore = 0.9
+	// fuzzyMinPrefix longitud mínima del término para contar coincidencias por prefijo
+	fuzzyMinPrefix = 3
+	// maxFuzzyTerms palabras de la consulta que se consideran
+	maxFuzzyTerms = 8
+)
+
+// fuzzyIndex índice de palabras de los nombres para búsqueda tolerante a errores.
+// Las palabras distintas son muchas menos que los contactos, así que la distancia de edición
+// se calcula sobre el vocabulario (filtrado por bigramas) y no sobre cada contacto.
+// Se usan bigramas y no trigramas para no perder transposiciones en palabras cortas ("jaun"/"juan").
+type fuzzyIndex struct {
+	version   uint64
+	contactos []models.Contacto  // Contactos indexados; las posiciones de search se refieren a ellos
+	words     []string           // Vocabulario
+	postings  [][]int32          // Palabra -> posiciones de contactos que la contienen
+	bigrams   map[string][]int32 // Bigrama -> palabras que lo contienen
+}
+
+// buildFuzzyIndex indexa las palabras del nombre de cada contacto
+func buildFuzzyIndex(version uint64, contactos []models.Contacto) *fuzzyIndex {
+	index := &fuzzyIndex{
+		version:   version,
+		contactos: contactos,
+		bigrams:   make(map[string][]int32),
+	}
+
+	wordIDs := make(map[string]int32)
+	for i, contacto := range contactos {
+		for _, word := range fuzzyWords(contacto.Nombre) {
+			id, ok := wordIDs[word]
+			if !ok {
+				id = int32(len(index.words))
+				wordIDs[word] = id
+				index.words = append(index.words, word)
+				index.postings = append(index.postings, nil)
+				for _, bigram := range wordBigrams(word) {
+					index.bigrams[bigram] = append(index.bigrams[bigram], id)
+				}
+			}
+			// Una palabra repetida en el mismo nombre se registra una vez
+			if postings := index.postings[id]; len(postings) == 0 || postings[len(postings)-1] != int32(i) {
+				index.postings[id] = append(postings, int32(i))
+			}
+		}
+	}
+
+	return index
+}
+
+// search califica los contactos contra la consulta; retorna posiciones y similitudes ordenadas
+// de mayor a menor similitud (empates en el orden original)
+func (index *fuzzyIndex) search(query string, minScore float64) ([]int32, []float64) {
+	terms := fuzzyWords(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	if len(terms) > maxFuzzyTerms {
+		terms = terms[:maxFuzzyTerms]
+	}
+
+	// Mejor similitud de cada término por contacto
+	best := make(map[int32][]float64)
+	for t, term := range terms {
+		for wordID, similarity := range index.similarWords(term, minScore) {
+			for _, pos := range index.postings[wordID] {
+				scores, ok := best[pos]
+				if !ok {
+					scores = make([]float64, len(terms))
+					best[pos] = scores
+				}
+				if similarity > scores[t] {
+					scores[t] = similarity
+				}
+			}
+		}
+	}
+
+	positions := make([]int32, 0, len(best))
+	scoreByPos := make(map[int32]float64, len(best))
+	for pos, scores := range best {
+		total := 0.0
+		for _, score := range scores {
+			total += score
+		}
+		if score := total / float64(len(terms)); score >= minScore {
+			positions = append(positions, pos)
+			scoreByPos[pos] = score
+		}
+	}
+
+	sort.Slice(positions, func(i, j int) bool {
+		si, sj := scoreByPos[positions[i]], scoreByPos[positions[j]]
+		if si != sj {
+			return si > sj
+		}
+		return positions[i] < positions[j]
+	})
+
+	scores := make([]float64, len(positions))
+	for i, pos := range positions {
+		scores[i] = scoreByPos[pos]
+	}
+	return positions, scores
+}
+
+// similarWords palabras del vocabulario parecidas al término, con su similitud
+func (index *fuzzyIndex) similarWords(term string, minScore float64) map[int32]float64 {
+	termRunes := []rune(term)
+	similar := make(map[int32]float64)
+
+	seen := make(map[int32]struct{})
+	for _, bigram := range wordBigrams(term) {
+		for _, wordID := range index.bigrams[bigram] {
+			if _, ok := seen[wordID]; ok {
+				continue
+			}
+			seen[wordID] = struct{}{}
+
+			if similarity := wordSimilarity(termRunes, []rune(index.words[wordID]), minScore); similarity > 0 {
+				similar[wordID] = similarity
+			}
+		}
+	}
+	return similar
+}
+
+// wordSimilarity similitud entre 0 y 1 basada en la distancia de edición (con transposiciones);
+// retorna 0 si no alcanza minScore
+func wordSimilarity(term, word []rune, minScore float64) float64 {
+	if len(term) >= fuzzyMinPrefix && len(word) > len(term) && string(word[:len(term)]) == string(term) {
+		return fuzzyPrefixScore
+	}
+
+	longest := len(term)
+	if len(word) > longest {
+		longest = len(word)
+	}
+	// La distancia nunca es menor a la diferencia de longitudes
+	maxDistance := int(float64(longest) * (1 - minScore))
+	if diff := len(term) - len(word); diff > maxDistance || -diff > maxDistance {
+		return 0
+	}
+
+	distance := editDistance(term, word)
+	if distance > maxDistance {
+		return 0
+	}
+	return 1 - float64(distance)/float64(longest)
+}
+
+// editDistance distancia de Damerau-Levenshtein (alineación óptima): "jaun" y "juan" están a 1
+func editDistance(a, b []rune) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return prev[len(b)]
+}
+
+// fuzzyWords separa un texto normalizado (sin acentos ni mayúsculas) en palabras
+func fuzzyWords(text string) []string {
+	return strings.FieldsFunc(utils.NormalizeText(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// wordBigrams bigramas de una palabra con marcas de inicio y fin ("$j", "ju", "ua", "an", "n$")
+func wordBigrams(word string) []string {
+	runes := append(append([]rune{'$'}, []rune(word)...), '$')
+
+	bigrams := make([]string, 0, len(runes)-1)
+	for i := 0; i+2 <= len(runes); i++ {
+		bigrams = append(bigrams, string(runes[i:i+2]))
+	}
+	return bigrams
+}
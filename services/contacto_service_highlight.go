@@ -0,0 +1,175 @@
+// services/contacto_service_highlight.go
+package services
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"contactos-api/models"
+	"contactos-api/querylang"
+	"contactos-api/utils"
+)
+
+// newFieldMatch coincidencia en [start, end) bytes del valor, con sus posiciones en runas
+func newFieldMatch(field querylang.Field, value string, start, end int) FieldMatch {
+	runeStart := utf8.RuneCountInString(value[:start])
+	return FieldMatch{
+		Field:     field.String(),
+		Start:     start,
+		End:       end,
+		RuneStart: runeStart,
+		RuneEnd:   runeStart + utf8.RuneCountInString(value[start:end]),
+	}
+}
+
+// normalizedText valor normalizado como NormalizeText, recordando de qué bytes del valor
+// original proviene cada byte normalizado para traducir las coincidencias
+type normalizedText struct {
+	value  string
+	norm   string
+	starts []int // Inicio, en el valor original, de la runa que produjo cada byte de norm
+	ends   []int // Fin, en el valor original, de esa runa
+}
+
+func normalizeWithOffsets(value string) normalizedText {
+	text := normalizedText{value: value}
+	var b strings.Builder
+	pendingSpace := false
+	for i, r := range value {
+		if unicode.IsSpace(r) {
+			pendingSpace = b.Len() > 0
+			continue
+		}
+		folded := utils.NormalizeText(string(r))
+		if folded == "" {
+			continue // Diacrítico combinado: se elimina
+		}
+
+		end := i + utf8.RuneLen(r)
+		if r == utf8.RuneError {
+			end = i + 1
+		}
+		if pendingSpace {
+			b.WriteByte(' ')
+			text.starts = append(text.starts, i)
+			text.ends = append(text.ends, i)
+			pendingSpace = false
+		}
+		for k := 0; k < len(folded); k++ {
+			text.starts = append(text.starts, i)
+			text.ends = append(text.ends, end)
+		}
+		b.WriteString(folded)
+	}
+	text.norm = b.String()
+	return text
+}
+
+// match traduce la coincidencia [start, end) del texto normalizado al valor original
+func (t normalizedText) match(field querylang.Field, start, end int) FieldMatch {
+	return newFieldMatch(field, t.value, t.starts[start], t.ends[end-1])
+}
+
+// byteRange rango [start, end) dentro del texto normalizado
+type byteRange struct{ start, end int }
+
+// mergeRanges ordena y une rangos que se traslapan o tocan
+func mergeRanges(ranges []byteRange) []byteRange {
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].start < ranges[j].start })
+	merged := ranges[:0]
+	for _, r := range ranges {
+		if n := len(merged); n > 0 && r.start <= merged[n-1].end {
+			if r.end > merged[n-1].end {
+				merged[n-1].end = r.end
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// substringHighlighter posiciones de cada término (ya normalizado) en todos los campos
+func substringHighlighter(terms []string) func(models.Contacto) []FieldMatch {
+	return func(contacto models.Contacto) []FieldMatch {
+		var matches []FieldMatch
+		for _, field := range searchFields {
+			text := normalizeWithOffsets(contactoFieldValue(contacto, field))
+
+			var ranges []byteRange
+			for _, term := range terms {
+				for from, found := 0, 0; found < maxFieldMatches; found++ {
+					i := strings.Index(text.norm[from:], term)
+					if i < 0 {
+						break
+					}
+					ranges = append(ranges, byteRange{from + i, from + i + len(term)})
+					from += i + len(term)
+				}
+			}
+
+			for i, r := range mergeRanges(ranges) {
+				if i == maxFieldMatches {
+					break
+				}
+				matches = append(matches, text.match(field, r.start, r.end))
+			}
+		}
+		return matches
+	}
+}
+
+// fuzzyHighlighter palabras del nombre parecidas a algún término; en coincidencias por
+// prefijo solo se marca el prefijo
+func fuzzyHighlighter(query string, minScore float64) func(models.Contacto) []FieldMatch {
+	terms := fuzzyWords(query)
+	if len(terms) > maxFuzzyTerms {
+		terms = terms[:maxFuzzyTerms]
+	}
+	termRunes := make([][]rune, len(terms))
+	for i, term := range terms {
+		termRunes[i] = []rune(term)
+	}
+
+	return func(contacto models.Contacto) []FieldMatch {
+		text := normalizeWithOffsets(contacto.Nombre)
+
+		var matches []FieldMatch
+		for _, word := range wordRanges(text.norm) {
+			wordText := text.norm[word.start:word.end]
+			for t, term := range terms {
+				if len(termRunes[t]) >= fuzzyMinPrefix && len(wordText) > len(term) && strings.HasPrefix(wordText, term) {
+					matches = append(matches, text.match(querylang.FieldNombre, word.start, word.start+len(term)))
+					break
+				}
+				if wordSimilarity(termRunes[t], []rune(wordText), minScore) > 0 {
+					matches = append(matches, text.match(querylang.FieldNombre, word.start, word.end))
+					break
+				}
+			}
+		}
+		return matches
+	}
+}
+
+// wordRanges palabras de un texto normalizado, separadas igual que fuzzyWords
+func wordRanges(text string) []byteRange {
+	var words []byteRange
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case isWord && start < 0:
+			start = i
+		case !isWord && start >= 0:
+			words = append(words, byteRange{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		words = append(words, byteRange{start, len(text)})
+	}
+	return words
+}
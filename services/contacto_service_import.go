@@ -0,0 +1,340 @@
+// services/contacto_service_import.go
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"contactos-api/models"
+	"contactos-api/repositories"
+	"contactos-api/validators"
+)
+
+// Modos de importación
+const (
+	ImportModeReplace = "replace" // Reemplaza todo el contenido con el archivo
+	ImportModeUpsert  = "upsert"  // Inserta claves nuevas y actualiza las existentes
+	ImportModeInsert  = "insert"  // Solo inserta claves nuevas
+)
+
+// Formatos de archivo aceptados en la importación
+const (
+	ImportFormatXLSX = "xlsx"
+	ImportFormatCSV  = "csv"
+	ImportFormatVCF  = "vcf"
+)
+
+// importPlan cambios que aplicaría una importación sobre los datos actuales
+type importPlan struct {
+	fileName  string
+	format    string
+	mode      string
+	parsed    *repositories.ParseResult
+	inserts   []models.Contacto
+	updates   []models.Contacto
+	cambios   []models.ContactoChange // Detalle por campo de cada update
+	unchanged int
+	deletes   []models.Contacto
+	skipped   []int
+	conflicts []models.ImportConflict
+}
+
+// ImportContactos procesa un archivo subido y lo aplica según el modo indicado
+func (s *ContactoService) ImportContactos(fileName string, data []byte, mode string) (*models.ImportResult, []models.ErrorResponse, error) {
+	return s.importContactos(context.Background(), fileName, data, mode, nil)
+}
+
+// importContactos importa con cancelación y avance. Se puede cancelar al abrir el archivo y al validar
+// filas; si se cancela mientras espera a otra carga no aplica nada, pero una vez que empieza a
+// aplicar los cambios ya no se interrumpe
+func (s *ContactoService) importContactos(ctx context.Context, fileName string, data []byte, mode string, progress repositories.ProgressFunc) (*models.ImportResult, []models.ErrorResponse, error) {
+	bulk, ok := s.repo.(repositories.BulkContactoRepository)
+	if !ok {
+		return nil, nil, fmt.Errorf("importación no disponible")
+	}
+
+	parsed, format, errores, err := parseImportFile(ctx, fileName, data, mode, s.validator, progress)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(errores) > 0 {
+		return nil, errores, nil
+	}
+
+	s.loadMu.Lock()
+	defer s.loadMu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	if progress != nil {
+		progress(repositories.ParseProgress{Phase: JobPhaseApplying})
+	}
+
+	previo, err := s.captureSnapshot()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error obteniendo contactos actuales: %w", err)
+	}
+
+	plan := buildImportPlan(parsed, mode, previo)
+	plan.fileName = fileName
+	plan.format = format
+
+	if err := s.applyImportPlan(bulk, plan, previo); err != nil {
+		return nil, nil, err
+	}
+
+	return plan.result(), nil, nil
+}
+
+// validateImportRequest valida modo y formato antes de procesar el archivo
+func validateImportRequest(fileName, mode string) []models.ErrorResponse {
+	switch mode {
+	case ImportModeReplace, ImportModeUpsert, ImportModeInsert:
+	default:
+		return []models.ErrorResponse{{
+			Campo:   "mode",
+			Mensaje: fmt.Sprintf("Modo '%s' inválido. Use replace, upsert o insert", mode),
+		}}
+	}
+
+	switch importFormat(fileName) {
+	case ImportFormatXLSX, ImportFormatCSV, ImportFormatVCF:
+	default:
+		return []models.ErrorResponse{{
+			Campo:   "file",
+			Mensaje: "Formato no soportado. Use archivos .xlsx, .csv o .vcf",
+		}}
+	}
+
+	return nil
+}
+
+// importFormat deduce el formato por la extensión del archivo
+func importFormat(fileName string) string {
+	return strings.TrimPrefix(strings.ToLower(filepath.Ext(fileName)), ".")
+}
+
+// parseImportFile valida modo y formato, y procesa el archivo con el pipeline de carga y las
+// reglas de validator. Solo retorna error si el contexto fue cancelado.
+func parseImportFile(ctx context.Context, fileName string, data []byte, mode string, validator *validators.ContactoValidator, progress repositories.ProgressFunc) (*repositories.ParseResult, string, []models.ErrorResponse, error) {
+	if errores := validateImportRequest(fileName, mode); len(errores) > 0 {
+		return nil, "", errores, nil
+	}
+
+	format := importFormat(fileName)
+
+	var parsed *repositories.ParseResult
+	var err error
+	switch format {
+	case ImportFormatXLSX:
+		parsed, err = repositories.ParseExcelBinaryContext(ctx, data, validator, progress)
+	case ImportFormatCSV:
+		// El CSV no conoce su total de antemano: estimarlo por líneas (menos el encabezado)
+		if progress != nil {
+			total := bytes.Count(data, []byte("\n")) - 1
+			inner := progress
+			progress = func(p repositories.ParseProgress) {
+				if p.Total == 0 && total > 0 {
+					p.Total = total
+				}
+				inner(p)
+			}
+		}
+		parsed, err = repositories.ParseCSVContext(ctx, bytes.NewReader(data), validator, progress)
+	case ImportFormatVCF:
+		parsed, err = repositories.ParseVCardContext(ctx, bytes.NewReader(data), validator, progress)
+	}
+
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, "", nil, err
+		}
+		return nil, "", []models.ErrorResponse{{
+			Campo:   "file",
+			Mensaje: fmt.Sprintf("No se pudo procesar el archivo: %v", err),
+		}}, nil
+	}
+
+	// Origen que se registra a los contactos importados (faceta source)
+	parsed.Source = repositories.SourceLabel(filepath.Base(fileName), parsed.Sheet)
+	return parsed, format, nil, nil
+}
+
+// buildImportPlan compara el archivo procesado con los datos actuales
+func buildImportPlan(parsed *repositories.ParseResult, mode string, actual *loadSnapshot) *importPlan {
+	plan := &importPlan{
+		mode:      mode,
+		parsed:    parsed,
+		inserts:   []models.Contacto{},
+		updates:   []models.Contacto{},
+		cambios:   []models.ContactoChange{},
+		deletes:   []models.Contacto{},
+		skipped:   []int{},
+		conflicts: []models.ImportConflict{},
+	}
+
+	assignMissingClaves(parsed, actual)
+
+	enArchivo := make(map[int]struct{}, len(parsed.Contactos))
+	for _, contacto := range parsed.Contactos {
+		enArchivo[contacto.ClaveCliente] = struct{}{}
+
+		existente, existe := actual.porClave[contacto.ClaveCliente]
+		if !existe {
+			plan.inserts = append(plan.inserts, contacto)
+			continue
+		}
+		if existente == contacto {
+			plan.unchanged++
+			continue
+		}
+
+		conflict := models.ImportConflict{
+			ClaveCliente: contacto.ClaveCliente,
+			Existing:     existente,
+			Incoming:     contacto,
+			Changes:      diffContacto(existente, contacto),
+			Resolution:   "sobrescribir",
+		}
+
+		if mode == ImportModeInsert {
+			conflict.Resolution = "omitir"
+			plan.skipped = append(plan.skipped, contacto.ClaveCliente)
+		} else {
+			plan.updates = append(plan.updates, contacto)
+			plan.cambios = append(plan.cambios, models.ContactoChange{
+				ClaveCliente: contacto.ClaveCliente,
+				Before:       existente,
+				After:        contacto,
+				Changes:      conflict.Changes,
+			})
+		}
+		plan.conflicts = append(plan.conflicts, conflict)
+	}
+
+	if mode == ImportModeReplace {
+		for _, contacto := range actual.contactos {
+			if _, sigue := enArchivo[contacto.ClaveCliente]; !sigue {
+				plan.deletes = append(plan.deletes, contacto)
+			}
+		}
+	}
+
+	return plan
+}
+
+// assignMissingClaves asigna claves nuevas a los contactos sin clave (p. ej. vCards de teléfonos),
+// a continuación de la mayor clave existente en los datos actuales o en el archivo
+func assignMissingClaves(parsed *repositories.ParseResult, actual *loadSnapshot) {
+	maxClave := 0
+	for _, contacto := range actual.contactos {
+		if contacto.ClaveCliente > maxClave {
+			maxClave = contacto.ClaveCliente
+		}
+	}
+	for _, contacto := range parsed.Contactos {
+		if contacto.ClaveCliente > maxClave {
+			maxClave = contacto.ClaveCliente
+		}
+	}
+
+	for i := range parsed.Contactos {
+		if parsed.Contactos[i].ClaveCliente == 0 {
+			maxClave++
+			parsed.Contactos[i].ClaveCliente = maxClave
+		}
+	}
+}
+
+// applyImportPlan persiste el plan con un solo guardado (requiere loadMu tomado)
+func (s *ContactoService) applyImportPlan(bulk repositories.BulkContactoRepository, plan *importPlan, previo *loadSnapshot) error {
+	if plan.mode == ImportModeReplace {
+		if err := bulk.ReplaceAll(plan.parsed); err != nil {
+			return fmt.Errorf("error reemplazando contactos: %w", err)
+		}
+	} else if len(plan.inserts)+len(plan.updates) > 0 {
+		cambios := make([]models.Contacto, 0, len(plan.inserts)+len(plan.updates))
+		cambios = append(cambios, plan.inserts...)
+		cambios = append(cambios, plan.updates...)
+
+		if err := bulk.UpsertMany(cambios, plan.parsed.Source); err != nil {
+			return fmt.Errorf("error guardando contactos importados: %w", err)
+		}
+
+		if len(plan.inserts) > 0 {
+			s.events.publish(models.EventCreated, EventSourceImport, clavesDe(plan.inserts))
+		}
+		if len(plan.updates) > 0 {
+			s.events.publish(models.EventUpdated, EventSourceImport, clavesDe(plan.updates))
+		}
+	}
+
+	// Toda importación cuenta como una nueva carga para el reporte previo y el diff; upsert e
+	// insert ya publicaron sus altas y cambios, solo el reemplazo se anuncia como recarga
+	if actual, err := s.captureSnapshot(); err == nil {
+		s.recordLoad(previo, actual, s.repo.GetLoadErrors(), actual.invalidos)
+		diff := diffSnapshots(previo, actual)
+		s.lastDiff.Store(diff)
+		if plan.mode == ImportModeReplace {
+			s.publishDiff(EventSourceImport, diff)
+		}
+	}
+
+	if contactos, err := s.repo.GetAll(); err == nil {
+		s.stats.reset(contactos)
+	}
+	s.dataChanged()
+
+	return nil
+}
+
+// clavesDe extrae las claves de una lista de contactos
+func clavesDe(contactos []models.Contacto) []int {
+	claves := make([]int, len(contactos))
+	for i, contacto := range contactos {
+		claves[i] = contacto.ClaveCliente
+	}
+	return claves
+}
+
+// preview arma la vista previa del plan sin aplicarlo
+func (p *importPlan) preview() *models.ImportPreview {
+	return &models.ImportPreview{
+		FileName: p.fileName,
+		Format:   p.format,
+		Mode:     p.mode,
+		Counts: models.ImportCounts{
+			Inserts:   len(p.inserts),
+			Updates:   len(p.updates),
+			Unchanged: p.unchanged,
+			Deletes:   len(p.deletes),
+			Rejected:  len(p.parsed.InvalidRowsData),
+			Conflicts: len(p.conflicts),
+		},
+		Inserts:   p.inserts,
+		Updates:   p.cambios,
+		Deletes:   p.deletes,
+		Rejected:  p.parsed.InvalidRowsData,
+		Conflicts: p.conflicts,
+		Report:    buildValidationReport(len(p.parsed.Contactos), p.parsed.LoadErrors, p.parsed.InvalidRowsData, defaultTopErrors),
+	}
+}
+
+// result arma la respuesta de la importación con el reporte de validación del archivo
+func (p *importPlan) result() *models.ImportResult {
+	return &models.ImportResult{
+		FileName:  p.fileName,
+		Format:    p.format,
+		Mode:      p.mode,
+		Inserted:  len(p.inserts),
+		Updated:   len(p.updates),
+		Unchanged: p.unchanged,
+		Deleted:   len(p.deletes),
+		Skipped:   p.skipped,
+		Report:    buildValidationReport(len(p.parsed.Contactos), p.parsed.LoadErrors, p.parsed.InvalidRowsData, defaultTopErrors),
+	}
+}
@@ -10,10 +10,38 @@ import (
 // 🆕 NUEVA ESTRUCTURA PARA PAGINACIÓN
 type PaginatedResult struct {
 	Data       []models.Contacto `json:"data"`
+	Hits       []SearchHit       `json:"hits,omitempty"` // Relevancia o coincidencias de cada elemento de Data (difusa, regex)
 	Page       int               `json:"page"`
 	Size       int               `json:"size"`
 	Total      int               `json:"total"`
 	TotalPages int               `json:"totalPages"`
 	HasNext    bool              `json:"hasNext"`
 	HasPrev    bool              `json:"hasPrev"`
+	
+	// Partial motivo si la búsqueda se detuvo por presupuesto (regex); Total cuenta lo encontrado hasta entonces
+	Partial string `json:"partial,omitempty"`
+	
+	// Facets conteos pedidos con facets=, sobre todos los resultados y no solo la página
+	Facets map[string]*Facet `json:"facets,omitempty"`
+	
+	// Next y Prev cursores opacos de la página siguiente y anterior (solo paginación por cursor)
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// SearchHit relevancia de un resultado de búsqueda
+type SearchHit struct {
+	ClaveCliente int          `json:"claveCliente"`
+	Score        float64      `json:"score,omitempty"`
+	Matches      []FieldMatch `json:"matches,omitempty"`
+}
+
+// FieldMatch coincidencia dentro de un campo; Start y End son posiciones en bytes del valor original,
+// RuneStart y RuneEnd las mismas en caracteres (runas)
+type FieldMatch struct {
+	Field     string `json:"field"`
+	Start     int    `json:"start"`
+	End       int    `json:"end"`
+	RuneStart int    `json:"runeStart"`
+	RuneEnd   int    `json:"runeEnd"`
 }
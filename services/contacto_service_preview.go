@@ -0,0 +1,168 @@
+// services/contacto_service_preview.go
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"contactos-api/models"
+	"contactos-api/repositories"
+)
+
+const (
+	// previewTTL tiempo durante el cual se puede aplicar una vista previa
+	previewTTL = 15 * time.Minute
+	// maxStoredPreviews vistas previas guardadas a la vez (las más viejas se descartan)
+	maxStoredPreviews = 20
+)
+
+var (
+	// ErrPreviewNotFound el token no existe o ya expiró
+	ErrPreviewNotFound = errors.New("vista previa no encontrada o expirada")
+	// ErrPreviewStale los datos cambiaron desde que se generó la vista previa
+	ErrPreviewStale = errors.New("los datos cambiaron desde la vista previa; genere una nueva")
+)
+
+// storedPreview plan pendiente de aplicar junto a la versión de datos sobre la que se calculó
+type storedPreview struct {
+	plan      *importPlan
+	version   uint64
+	createdAt time.Time
+	expiresAt time.Time
+}
+
+// previewStore guarda en memoria los planes generados en modo dry-run
+type previewStore struct {
+	previews map[string]*storedPreview
+	mu       sync.Mutex
+}
+
+func newPreviewStore() *previewStore {
+	return &previewStore{previews: make(map[string]*storedPreview)}
+}
+
+// add guarda un plan y retorna su token
+func (ps *previewStore) add(plan *importPlan, version uint64) (string, *storedPreview, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", nil, fmt.Errorf("error generando token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	now := time.Now()
+	stored := &storedPreview{plan: plan, version: version, createdAt: now, expiresAt: now.Add(previewTTL)}
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	ps.purge(now)
+	if len(ps.previews) >= maxStoredPreviews {
+		// Descartar la más antigua
+		var oldestToken string
+		var oldest time.Time
+		for t, p := range ps.previews {
+			if oldestToken == "" || p.createdAt.Before(oldest) {
+				oldestToken, oldest = t, p.createdAt
+			}
+		}
+		delete(ps.previews, oldestToken)
+	}
+	ps.previews[token] = stored
+
+	return token, stored, nil
+}
+
+// take retira un plan del store (solo se puede aplicar una vez)
+func (ps *previewStore) take(token string) (*storedPreview, bool) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	ps.purge(time.Now())
+	stored, ok := ps.previews[token]
+	if ok {
+		delete(ps.previews, token)
+	}
+	return stored, ok
+}
+
+// purge elimina las vistas previas expiradas (requiere mu tomado)
+func (ps *previewStore) purge(now time.Time) {
+	for token, stored := range ps.previews {
+		if now.After(stored.expiresAt) {
+			delete(ps.previews, token)
+		}
+	}
+}
+
+// PreviewImport calcula el plan de una importación sin persistir nada
+func (s *ContactoService) PreviewImport(fileName string, data []byte, mode string) (*models.ImportPreview, []models.ErrorResponse, error) {
+	if _, ok := s.repo.(repositories.BulkContactoRepository); !ok {
+		return nil, nil, fmt.Errorf("importación no disponible")
+	}
+
+	parsed, format, errores, err := parseImportFile(context.Background(), fileName, data, mode, s.validator, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(errores) > 0 {
+		return nil, errores, nil
+	}
+
+	s.loadMu.Lock()
+	defer s.loadMu.Unlock()
+
+	actual, err := s.captureSnapshot()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error obteniendo contactos actuales: %w", err)
+	}
+
+	plan := buildImportPlan(parsed, mode, actual)
+	plan.fileName = fileName
+	plan.format = format
+
+	token, stored, err := s.previews.add(plan, s.version.Load())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	preview := plan.preview()
+	preview.Token = token
+	preview.ExpiresAt = stored.expiresAt.Format(time.RFC3339)
+
+	return preview, nil, nil
+}
+
+// ApplyImportPreview aplica un plan generado en dry-run si los datos no cambiaron
+func (s *ContactoService) ApplyImportPreview(token string) (*models.ImportResult, error) {
+	bulk, ok := s.repo.(repositories.BulkContactoRepository)
+	if !ok {
+		return nil, fmt.Errorf("importación no disponible")
+	}
+
+	s.loadMu.Lock()
+	defer s.loadMu.Unlock()
+
+	stored, ok := s.previews.take(token)
+	if !ok {
+		return nil, ErrPreviewNotFound
+	}
+	if stored.version != s.version.Load() {
+		return nil, ErrPreviewStale
+	}
+
+	previo, err := s.captureSnapshot()
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo contactos actuales: %w", err)
+	}
+
+	if err := s.applyImportPlan(bulk, stored.plan, previo); err != nil {
+		return nil, err
+	}
+
+	return stored.plan.result(), nil
+}
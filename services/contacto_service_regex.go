@@ -0,0 +1,134 @@
+// services/contacto_service_regex.go
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"contactos-api/models"
+	"contactos-api/querylang"
+)
+
+const (
+	// MaxRegexLength longitud máxima del patrón en bytes
+	MaxRegexLength = 256
+	// RegexTimeBudget tiempo máximo de recorrido por consulta
+	RegexTimeBudget = 2 * time.Second
+	// RegexRowBudget coincidencias máximas por consulta; al alcanzarlo se detiene el recorrido
+	RegexRowBudget = 20000
+	// maxFieldMatches coincidencias por campo reportadas para resaltar
+	maxFieldMatches = 10
+	// regexCheckInterval cada cuántas filas se revisa el tiempo transcurrido
+	regexCheckInterval = 1024
+)
+
+// Motivos de resultados parciales
+const (
+	PartialReasonTime = "tiempo" // Se agotó RegexTimeBudget
+	PartialReasonRows = "filas"  // Se alcanzó RegexRowBudget
+)
+
+var (
+	// ErrPartialSearch la búsqueda se detuvo por presupuesto; los recorridos completos
+	// (exportación, /buscar) no retornan resultados incompletos
+	ErrPartialSearch = errors.New("la búsqueda se detuvo por presupuesto")
+)
+
+// searchFields campos en los que se busca y resalta, en orden de reporte
+var searchFields = []querylang.Field{
+	querylang.FieldClave, querylang.FieldNombre, querylang.FieldCorreo, querylang.FieldTelefono,
+}
+
+// CompileSearchRegex valida y compila un patrón RE2 para el modo regex
+func CompileSearchRegex(pattern string) (*regexp.Regexp, error) {
+	if len(pattern) > MaxRegexLength {
+		return nil, fmt.Errorf("el patrón supera los %d caracteres", MaxRegexLength)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("expresión regular inválida: %w", err)
+	}
+	return re, nil
+}
+
+// contactoFieldValue texto original de un campo (la clave en decimal)
+func contactoFieldValue(contacto models.Contacto, field querylang.Field) string {
+	switch field {
+	case querylang.FieldNombre:
+		return contacto.Nombre
+	case querylang.FieldCorreo:
+		return contacto.Correo
+	case querylang.FieldTelefono:
+		return contacto.TelefonoContacto
+	default:
+		return strconv.Itoa(contacto.ClaveCliente)
+	}
+}
+
+// regexMatcher patrón compilado con el campo al que aplica (FieldAny = todos)
+type regexMatcher struct {
+	re    *regexp.Regexp
+	field querylang.Field
+}
+
+func (m *regexMatcher) fields() []querylang.Field {
+	if m.field == querylang.FieldAny {
+		return searchFields
+	}
+	return []querylang.Field{m.field}
+}
+
+func (m *regexMatcher) match(contacto models.Contacto) bool {
+	for _, field := range m.fields() {
+		if m.re.MatchString(contactoFieldValue(contacto, field)) {
+			return true
+		}
+	}
+	return false
+}
+
+// highlight posiciones del valor original donde coincide el patrón
+func (m *regexMatcher) highlight(contacto models.Contacto) []FieldMatch {
+	var matches []FieldMatch
+	for _, field := range m.fields() {
+		value := contactoFieldValue(contacto, field)
+		for _, loc := range m.re.FindAllStringIndex(value, maxFieldMatches) {
+			matches = append(matches, newFieldMatch(field, value, loc[0], loc[1]))
+		}
+	}
+	return matches
+}
+
+// regexContactos recorre los contactos con el patrón respetando los presupuestos de tiempo y
+// coincidencias; si alguno se agota el resultado es parcial
+func regexContactos(opts SearchOptions, allContactos []models.Contacto) (*searchResult, error) {
+	re, err := CompileSearchRegex(opts.Query)
+	if err != nil {
+		return nil, err
+	}
+	matcher := &regexMatcher{re: re, field: opts.Field}
+
+	result := &searchResult{contactos: []models.Contacto{}}
+	if opts.Highlight {
+		result.highlight = matcher.highlight
+	}
+	deadline := time.Now().Add(RegexTimeBudget)
+	for i, contacto := range allContactos {
+		if i%regexCheckInterval == 0 && i > 0 && time.Now().After(deadline) {
+			result.partial = PartialReasonTime
+			break
+		}
+		if !matcher.match(contacto) {
+			continue
+		}
+		if len(result.contactos) == RegexRowBudget {
+			result.partial = PartialReasonRows
+			break
+		}
+		result.contactos = append(result.contactos, contacto)
+	}
+	return result, nil
+}
@@ -0,0 +1,127 @@
+// services/contacto_service_report.go
+package services
+
+import (
+	"sort"
+	"time"
+
+	"contactos-api/models"
+)
+
+const (
+	// defaultTopErrors cantidad de errores comunes incluidos en el resumen
+	defaultTopErrors = 10
+	// maxTopErrors límite superior para el parámetro top
+	maxTopErrors = 100
+	// maxSampleRows filas de ejemplo guardadas por cada error común
+	maxSampleRows = 5
+)
+
+// Tipos de error usados para agrupar en el resumen (los códigos que asigna el parser)
+const (
+	ErrorTypeVacio      = models.RowErrorVacio
+	ErrorTypeDuplicado  = models.RowErrorDuplicado
+	ErrorTypeLongitud   = models.RowErrorLongitud
+	ErrorTypeNumerico   = models.RowErrorNumerico
+	ErrorTypeCorreo     = models.RowErrorCorreo
+	ErrorTypeEstructura = models.RowErrorEstructura
+	ErrorTypeOtro       = "otro"
+)
+
+// buildValidationReport arma el reporte de validación a partir del estado cargado
+func buildValidationReport(validRows int, loadErrors []models.RowError, invalidRowsData []models.RowData, topErrors int) *models.ExcelValidationReport {
+	totalRows := validRows + len(invalidRowsData)
+
+	return &models.ExcelValidationReport{
+		TotalRows:       totalRows,
+		ValidRows:       validRows,
+		InvalidRows:     len(invalidRowsData),
+		Errors:          loadErrors,
+		InvalidRowsData: invalidRowsData,
+		LoadTimestamp:   time.Now().Format("2006-01-02 15:04:05"),
+		Summary:         buildReportSummary(loadErrors, totalRows, topErrors),
+	}
+}
+
+// buildReportSummary calcula conteos por campo y tipo, y los errores más comunes
+func buildReportSummary(loadErrors []models.RowError, totalRows int, topErrors int) *models.ReportSummary {
+	if topErrors <= 0 {
+		topErrors = defaultTopErrors
+	}
+	if topErrors > maxTopErrors {
+		topErrors = maxTopErrors
+	}
+
+	summary := &models.ReportSummary{
+		TotalErrors:      len(loadErrors),
+		ErrorsByField:    make(map[string]int),
+		ErrorsByType:     make(map[string]int),
+		MostCommonErrors: []models.CommonError{},
+	}
+
+	affected := make(map[int]struct{})
+	common := make(map[string]*models.CommonError)
+
+	for _, rowError := range loadErrors {
+		errorType := classifyRowError(rowError)
+
+		summary.ErrorsByField[rowError.Field]++
+		summary.ErrorsByType[errorType]++
+		affected[rowError.Row] = struct{}{}
+
+		// Agrupar por campo + mensaje
+		key := rowError.Field + "|" + rowError.Error
+		entry, exists := common[key]
+		if !exists {
+			entry = &models.CommonError{
+				Message:    rowError.Error,
+				Field:      rowError.Field,
+				Type:       errorType,
+				SampleRows: []int{},
+			}
+			common[key] = entry
+		}
+		entry.Count++
+
+		if len(entry.SampleRows) < maxSampleRows &&
+			(len(entry.SampleRows) == 0 || entry.SampleRows[len(entry.SampleRows)-1] != rowError.Row) {
+			entry.SampleRows = append(entry.SampleRows, rowError.Row)
+		}
+	}
+
+	summary.AffectedRows = len(affected)
+	if totalRows > 0 {
+		summary.AffectedRowsPercent = float64(summary.AffectedRows) / float64(totalRows) * 100
+	}
+
+	for _, entry := range common {
+		summary.MostCommonErrors = append(summary.MostCommonErrors, *entry)
+	}
+
+	// Ordenar por frecuencia (y por mensaje para un orden estable)
+	sort.Slice(summary.MostCommonErrors, func(i, j int) bool {
+		a, b := summary.MostCommonErrors[i], summary.MostCommonErrors[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Field != b.Field {
+			return a.Field < b.Field
+		}
+		return a.Message < b.Message
+	})
+
+	if len(summary.MostCommonErrors) > topErrors {
+		summary.MostCommonErrors = summary.MostCommonErrors[:topErrors]
+	}
+
+	return summary
+}
+
+// classifyRowError retorna el tipo de error asignado al detectarlo; el mensaje no se
+// interpreta porque puede cambiar (o configurarse) sin que cambie el tipo
+func classifyRowError(rowError models.RowError) string {
+	if rowError.Code == "" {
+		return ErrorTypeOtro
+	}
+	return rowError.Code
+}
@@ -0,0 +1,214 @@
+// services/contacto_service_rules.go
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"contactos-api/models"
+	"contactos-api/validators"
+)
+
+const (
+	defaultRuleSamples = 10
+	maxRuleSamples     = 100
+)
+
+// ruleRow valores en texto de una fila evaluada por el probador de reglas
+type ruleRow struct {
+	source string
+	row    int
+	values map[string]string
+}
+
+// TestRules evalúa patrones candidatos contra los contactos cargados y las filas inválidas
+// sin modificar nada; compara cada campo probado con la regla activa del validador
+func (s *ContactoService) TestRules(request *models.RuleTestRequest) (*models.RuleTestResult, []models.ErrorResponse, error) {
+	rules, compiled, errores := compileCandidateRules(request)
+	if len(errores) > 0 {
+		return nil, errores, nil
+	}
+
+	samples := request.Samples
+	if samples <= 0 {
+		samples = defaultRuleSamples
+	}
+	if samples > maxRuleSamples {
+		samples = maxRuleSamples
+	}
+
+	snapshot, err := s.captureSnapshot()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error obteniendo contactos: %w", err)
+	}
+
+	fields := make([]string, 0, len(compiled))
+	for _, campo := range validators.CamposValidados {
+		if _, ok := compiled[campo]; ok {
+			fields = append(fields, campo)
+		}
+	}
+
+	result := &models.RuleTestResult{
+		Rules:              rules,
+		ContactosEvaluados: len(snapshot.contactos),
+		InvalidosEvaluados: len(snapshot.invalidos),
+		NewlyFailingRows:   []models.RuleTestSample{},
+		NewlyPassingRows:   []models.RuleTestSample{},
+	}
+	impacts := make(map[string]*models.RuleFieldImpact, len(fields))
+	for _, campo := range fields {
+		impacts[campo] = &models.RuleFieldImpact{Field: campo, Pattern: rules[campo]}
+	}
+
+	validator := s.validator
+	evaluate := func(row ruleRow) {
+		result.RowsEvaluated++
+
+		currentOK, candidateOK := true, true
+		var changed []string
+		for _, campo := range fields {
+			value := row.values[campo]
+			current := validator.ValidarCampo(campo, value) == nil
+			candidate := compiled[campo].MatchString(value)
+
+			impact := impacts[campo]
+			if !current {
+				impact.CurrentFailures++
+				currentOK = false
+			}
+			if !candidate {
+				impact.CandidateFailures++
+				candidateOK = false
+			}
+			if current != candidate {
+				changed = append(changed, campo)
+				if current {
+					impact.NewlyFailing++
+				} else {
+					impact.NewlyPassing++
+				}
+			}
+		}
+
+		if !currentOK {
+			result.CurrentlyFailing++
+		}
+		if !candidateOK {
+			result.CandidateFailing++
+		}
+		switch {
+		case currentOK && !candidateOK:
+			result.NewlyFailing++
+			if len(result.NewlyFailingRows) < samples {
+				result.NewlyFailingRows = append(result.NewlyFailingRows, row.sample(changed))
+			}
+		case !currentOK && candidateOK:
+			result.NewlyPassing++
+			if len(result.NewlyPassingRows) < samples {
+				result.NewlyPassingRows = append(result.NewlyPassingRows, row.sample(changed))
+			}
+		}
+	}
+
+	for _, contacto := range snapshot.contactos {
+		evaluate(ruleRow{source: models.RuleSampleContacto, values: map[string]string{
+			"claveCliente":     strconv.Itoa(contacto.ClaveCliente),
+			"nombre":           contacto.Nombre,
+			"correo":           contacto.Correo,
+			"telefonoContacto": contacto.TelefonoContacto,
+		}})
+	}
+	for _, rowData := range snapshot.invalidos {
+		evaluate(ruleRow{source: models.RuleSampleInvalida, row: rowData.Row, values: map[string]string{
+			"claveCliente":     rowData.ClaveCliente,
+			"nombre":           rowData.Nombre,
+			"correo":           rowData.Correo,
+			"telefonoContacto": rowData.TelefonoContacto,
+		}})
+	}
+
+	for _, campo := range fields {
+		result.Fields = append(result.Fields, *impacts[campo])
+	}
+	return result, nil, nil
+}
+
+// compileCandidateRules reúne las reglas de la petición (campo único o conjunto) y las compila
+// anclados como ^(?:patrón)$: igual que las reglas del validador, el valor completo debe coincidir
+func compileCandidateRules(request *models.RuleTestRequest) (map[string]string, map[string]*regexp.Regexp, []models.ErrorResponse) {
+	rules := make(map[string]string, len(request.Rules)+1)
+	for campo, pattern := range request.Rules {
+		rules[campo] = pattern
+	}
+
+	var errores []models.ErrorResponse
+	if request.Field != "" || request.Pattern != "" {
+		if request.Field == "" {
+			errores = append(errores, models.ErrorResponse{Campo: "field", Mensaje: "Indique el campo al que aplica el patrón"})
+		} else if _, repetido := rules[request.Field]; repetido {
+			errores = append(errores, models.ErrorResponse{Campo: "field", Mensaje: fmt.Sprintf("El campo '%s' también viene en rules", request.Field)})
+		} else {
+			rules[request.Field] = request.Pattern
+		}
+	}
+	if len(rules) == 0 && len(errores) == 0 {
+		errores = append(errores, models.ErrorResponse{Campo: "rules", Mensaje: "Indique field y pattern, o rules con al menos un campo"})
+	}
+
+	campos := make([]string, 0, len(rules))
+	for campo := range rules {
+		campos = append(campos, campo)
+	}
+	sort.Strings(campos)
+
+	compiled := make(map[string]*regexp.Regexp, len(rules))
+	for _, campo := range campos {
+		if !isValidatedField(campo) {
+			errores = append(errores, models.ErrorResponse{
+				Campo:   campo,
+				Mensaje: "Campo sin regla de validación. Use claveCliente, nombre, correo o telefonoContacto",
+			})
+			continue
+		}
+		if rules[campo] == "" {
+			errores = append(errores, models.ErrorResponse{Campo: campo, Mensaje: "El patrón no puede estar vacío"})
+			continue
+		}
+		if _, err := CompileSearchRegex(rules[campo]); err != nil {
+			errores = append(errores, models.ErrorResponse{Campo: campo, Mensaje: err.Error()})
+			continue
+		}
+		re, err := regexp.Compile(`^(?:` + rules[campo] + `)$`)
+		if err != nil {
+			errores = append(errores, models.ErrorResponse{Campo: campo, Mensaje: fmt.Sprintf("expresión regular inválida: %v", err)})
+			continue
+		}
+		compiled[campo] = re
+	}
+
+	return rules, compiled, errores
+}
+
+func isValidatedField(campo string) bool {
+	for _, validado := range validators.CamposValidados {
+		if campo == validado {
+			return true
+		}
+	}
+	return false
+}
+
+func (r ruleRow) sample(changed []string) models.RuleTestSample {
+	return models.RuleTestSample{
+		Source:           r.source,
+		Row:              r.row,
+		ClaveCliente:     r.values["claveCliente"],
+		Nombre:           r.values["nombre"],
+		Correo:           r.values["correo"],
+		TelefonoContacto: r.values["telefonoContacto"],
+		Changed:          changed,
+	}
+}
@@ -0,0 +1,193 @@
+// services/contacto_service_search.go
+package services
+
+import (
+	"fmt"
+	"math"
+
+	"contactos-api/models"
+	"contactos-api/querylang"
+	"contactos-api/repositories"
+)
+
+// Modos de búsqueda
+const (
+	SearchModeSubstring = "substring" // Subcadena en nombre, correo, teléfono o clave (default)
+	SearchModeFuzzy     = "fuzzy"     // Tolerante a errores en el nombre, ordenado por similitud
+	SearchModeQuery     = "query"     // Lenguaje de consulta con campos, comodines, rangos y operadores
+	SearchModeRegex     = "regex"     // Expresión regular RE2 sobre los valores originales
+)
+
+// SearchOptions criterios compartidos por listados, búsqueda y exportación
+type SearchOptions struct {
+	Query    string  // Término libre: nombre, correo, teléfono o clave
+	Mode     string  // SearchModeSubstring (default), SearchModeFuzzy, SearchModeQuery o SearchModeRegex
+	MinScore float64 // Similitud mínima en modo difuso (0 = DefaultFuzzyMinScore)
+
+	Field querylang.Field // Campo al que aplica el patrón en modo regex (FieldAny = todos)
+
+	Sort SortSpec // Orden de los resultados (vacío = orden de carga, o similitud en modo difuso)
+
+	Facets []string // Facetas a contar sobre todos los resultados (solo listados paginados)
+
+	Highlight bool // Reportar en qué campos y posiciones coincide cada resultado (substring, fuzzy, regex)
+}
+
+// searchResult contactos que cumplen los criterios, en el orden en que se retornan
+type searchResult struct {
+	contactos []models.Contacto
+	scores    []float64                          // Similitud de cada contacto (solo modo difuso)
+	highlight func(models.Contacto) []FieldMatch // Coincidencias para resaltar (si se pidieron)
+	partial   string                             // Motivo si el recorrido se detuvo antes de terminar
+}
+
+// filterContactos aplica los criterios de búsqueda sobre todos los contactos y los ordena;
+// los resultados ordenados se guardan por versión para no reordenar en cada página
+func (s *ContactoService) filterContactos(opts SearchOptions) (*searchResult, error) {
+	version := s.version.Load()
+	key := sortKey(opts)
+	if len(opts.Sort) > 0 {
+		if cached := s.sorting.cached(version, key); cached != nil {
+			return cached, nil
+		}
+	}
+
+	allContactos, err := s.repo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo contactos: %w", err)
+	}
+
+	result, err := s.matchContactos(version, opts, allContactos)
+	if err != nil || len(opts.Sort) == 0 {
+		return result, err
+	}
+	return s.sorting.sort(version, key, opts.Sort, allContactos, result), nil
+}
+
+// matchContactos selecciona los contactos que cumplen los criterios, en el orden del modo
+func (s *ContactoService) matchContactos(version uint64, opts SearchOptions, allContactos []models.Contacto) (*searchResult, error) {
+	if opts.Query == "" {
+		return &searchResult{contactos: allContactos}, nil
+	}
+
+	if opts.Mode == SearchModeFuzzy {
+		minScore := opts.MinScore
+		if minScore <= 0 {
+			minScore = DefaultFuzzyMinScore
+		}
+
+		index := s.fuzzy.get(version, allContactos)
+		positions, scores := index.search(opts.Query, minScore)
+		filteredContactos := make([]models.Contacto, len(positions))
+		for i, pos := range positions {
+			filteredContactos[i] = index.contactos[pos]
+		}
+		result := &searchResult{contactos: filteredContactos, scores: scores}
+		if opts.Highlight {
+			result.highlight = fuzzyHighlighter(opts.Query, minScore)
+		}
+		return result, nil
+	}
+
+	switch opts.Mode {
+	case SearchModeQuery:
+		filteredContactos, err := s.queryContactos(opts.Query, allContactos)
+		if err != nil {
+			return nil, err
+		}
+		return &searchResult{contactos: filteredContactos}, nil
+	case SearchModeRegex:
+		return regexContactos(opts, allContactos)
+	}
+
+	terms := repositories.SearchTerms(opts.Query)
+	result := &searchResult{}
+	if opts.Highlight {
+		result.highlight = substringHighlighter(terms)
+	}
+
+	// Subcadena sobre las claves normalizadas que mantiene el repositorio, si las tiene
+	if searcher, ok := s.repo.(repositories.TextSearcher); ok {
+		filteredContactos, err := searcher.SearchText(opts.Query)
+		if err != nil {
+			return nil, err
+		}
+		result.contactos = filteredContactos
+		return result, nil
+	}
+
+	for _, contacto := range allContactos {
+		if matchesTerms(contacto, terms) {
+			result.contactos = append(result.contactos, contacto)
+		}
+	}
+	return result, nil
+}
+
+// queryContactos evalúa una consulta del lenguaje de consulta; con el índice del repositorio
+// solo se evalúan los contactos que contienen las subcadenas obligatorias de la consulta
+func (s *ContactoService) queryContactos(query string, allContactos []models.Contacto) ([]models.Contacto, error) {
+	expr, err := querylang.Parse(query)
+	if err != nil {
+		return nil, fmt.Errorf("consulta inválida: %w", err)
+	}
+
+	if searcher, ok := s.repo.(repositories.TextSearcher); ok {
+		return searcher.FilterText(querylang.RequiredTerms(expr), expr.Match)
+	}
+
+	var filteredContactos []models.Contacto
+	for _, contacto := range allContactos {
+		if expr.Match(contacto, repositories.NewSearchKeys(contacto)) {
+			filteredContactos = append(filteredContactos, contacto)
+		}
+	}
+	return filteredContactos, nil
+}
+
+// matchesTerms búsqueda por subcadena sin acentos ni mayúsculas: cada término debe aparecer
+// en nombre, correo, teléfono o clave
+func matchesTerms(contacto models.Contacto, terms []string) bool {
+	return repositories.NewSearchKeys(contacto).MatchesAll(terms)
+}
+
+// buildHits arma relevancia y coincidencias de una página; offset es la posición de la página
+// en los resultados. Retorna nil si el modo no aporta ninguna de las dos.
+func (r *searchResult) buildHits(page []models.Contacto, offset int) []SearchHit {
+	if r.scores == nil && r.highlight == nil {
+		return nil
+	}
+
+	hits := make([]SearchHit, len(page))
+	for i, contacto := range page {
+		hits[i].ClaveCliente = contacto.ClaveCliente
+		if r.scores != nil {
+			hits[i].Score = math.Round(r.scores[offset+i]*1000) / 1000
+		}
+		if r.highlight != nil {
+			hits[i].Matches = r.highlight(contacto)
+		}
+	}
+	return hits
+}
+
+// ExportContactos recorre los contactos que cumplen los criterios; el resultado filtrado
+// (y ordenado) se arma completo en memoria como en SearchContactos y solo la escritura va
+// por partes: fn recibe cada contacto en orden y puede abortar el recorrido retornando error.
+// Si la búsqueda quedó incompleta retorna ErrPartialSearch sin llamar a fn.
+func (s *ContactoService) ExportContactos(opts SearchOptions, fn func(models.Contacto) error) error {
+	result, err := s.filterContactos(opts)
+	if err != nil {
+		return err
+	}
+	if result.partial != "" {
+		return fmt.Errorf("%w (%s); use un filtro más específico", ErrPartialSearch, result.partial)
+	}
+
+	for _, contacto := range result.contactos {
+		if err := fn(contacto); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+// services/contacto_service_sort.go
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"contactos-api/models"
+	"contactos-api/querylang"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+const (
+	// maxSortFields campos máximos en un criterio de orden
+	maxSortFields = 4
+	// maxSortIndexes órdenes completos guardados (uno por criterio)
+	maxSortIndexes = 4
+	// maxSortedResults resultados filtrados y ordenados guardados
+	maxSortedResults = 16
+	// maxSortedElements contactos guardados entre todos los resultados ordenados; cada
+	// resultado es una copia, así que el tope se fija por elementos y no solo por consultas
+	maxSortedElements = 200000
+)
+
+// SortField campo de orden; Desc invierte el sentido
+type SortField struct {
+	Field querylang.Field
+	Desc  bool
+}
+
+// SortSpec criterio de orden por varios campos; los empates se resuelven por ClaveCliente ascendente
+type SortSpec []SortField
+
+// ParseSortSpec interpreta "nombre,-claveCliente": campos separados por coma, '-' para descendente
+func ParseSortSpec(spec string) (SortSpec, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+
+	var result SortSpec
+	seen := make(map[querylang.Field]bool)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		desc := strings.HasPrefix(part, "-")
+		name := strings.TrimPrefix(strings.TrimPrefix(part, "-"), "+")
+
+		field, ok := querylang.LookupField(name)
+		if !ok || field == querylang.FieldAny {
+			return nil, fmt.Errorf("campo de orden '%s' inválido. Use nombre, correo, telefonoContacto o claveCliente", name)
+		}
+		if seen[field] {
+			return nil, fmt.Errorf("el campo '%s' se repite en el orden", field)
+		}
+		seen[field] = true
+		result = append(result, SortField{Field: field, Desc: desc})
+	}
+
+	if len(result) > maxSortFields {
+		return nil, fmt.Errorf("se permiten hasta %d campos de orden", maxSortFields)
+	}
+	return result, nil
+}
+
+// String forma canónica del criterio ("nombre,-claveCliente")
+func (spec SortSpec) String() string {
+	parts := make([]string, len(spec))
+	for i, field := range spec {
+		parts[i] = field.Field.String()
+		if field.Desc {
+			parts[i] = "-" + parts[i]
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// sortIndex posición de cada contacto en el orden completo de un criterio
+type sortIndex struct {
+	version uint64
+	spec    string
+	rank    map[int]int32 // ClaveCliente -> posición
+}
+
+// sortedResult resultado filtrado y ordenado de una consulta
+type sortedResult struct {
+	version uint64
+	key     string
+	result  *searchResult
+}
+
+// sortCache órdenes completos por criterio y resultados ordenados por consulta, por versión de datos.
+// Con el orden completo precalculado, ordenar un subconjunto solo compara enteros; con el resultado
+// guardado, las páginas siguientes de la misma consulta no vuelven a ordenar.
+type sortCache struct {
+	indexes []*sortIndex
+	results []sortedResult
+	mu      sync.Mutex
+}
+
+// SortContactos ordena una lista de contactos según el criterio usando el orden completo guardado
+func (s *ContactoService) SortContactos(contactos []models.Contacto, spec SortSpec) []models.Contacto {
+	if len(spec) == 0 || len(contactos) < 2 {
+		return contactos
+	}
+
+	version := s.version.Load()
+	allContactos, err := s.repo.GetAll()
+	if err != nil {
+		return contactos
+	}
+
+	s.sorting.mu.Lock()
+	rank := s.sorting.indexFor(version, spec, allContactos).rank
+	s.sorting.mu.Unlock()
+	return sortByRank(&searchResult{contactos: contactos}, rank).contactos
+}
+
+// sortKey identifica una consulta ordenada dentro de una versión de datos
+func sortKey(opts SearchOptions) string {
+	return fmt.Sprintf("%s|%s|%s|%g|%d|%t", opts.Sort, opts.Mode, opts.Query, opts.MinScore, opts.Field, opts.Highlight)
+}
+
+// cached retorna el resultado ordenado guardado de la consulta, si lo hay para esta versión
+func (c *sortCache) cached(version uint64, key string) *searchResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	kept := c.results[:0]
+	var found *searchResult
+	for _, cached := range c.results {
+		if cached.version != version {
+			continue // Datos viejos: descartar
+		}
+		if cached.key == key {
+			found = cached.result
+		}
+		kept = append(kept, cached)
+	}
+	c.results = kept
+	return found
+}
+
+// sort ordena el resultado según el criterio y lo guarda para las siguientes páginas
+func (c *sortCache) sort(version uint64, key string, spec SortSpec, allContactos []models.Contacto, result *searchResult) *searchResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	sorted := sortByRank(result, c.indexFor(version, spec, allContactos).rank)
+
+	// Un resultado que no cabe solo no se guarda: las siguientes páginas lo reordenan con el índice
+	size := len(sorted.contactos)
+	if size > maxSortedElements {
+		return sorted
+	}
+	total := size
+	for _, cached := range c.results {
+		total += len(cached.result.contactos)
+	}
+	for len(c.results) > 0 && (len(c.results) >= maxSortedResults || total > maxSortedElements) {
+		total -= len(c.results[0].result.contactos)
+		c.results[0] = sortedResult{} // Soltar la copia antes de recortar
+		c.results = c.results[1:]
+	}
+	c.results = append(c.results, sortedResult{version: version, key: key, result: sorted})
+	return sorted
+}
+
+// indexFor retorna el orden completo del criterio, calculándolo si los datos cambiaron (requiere mu)
+func (c *sortCache) indexFor(version uint64, spec SortSpec, allContactos []models.Contacto) *sortIndex {
+	name := spec.String()
+	kept := c.indexes[:0]
+	for _, index := range c.indexes {
+		if index.version != version {
+			continue // Datos viejos: descartar
+		}
+		if index.spec == name {
+			return index
+		}
+		kept = append(kept, index)
+	}
+	c.indexes = kept
+
+	index := buildSortIndex(version, spec, allContactos)
+	if len(c.indexes) >= maxSortIndexes {
+		c.indexes = c.indexes[1:]
+	}
+	c.indexes = append(c.indexes, index)
+	return index
+}
+
+// buildSortIndex ordena todos los contactos con intercalación española (ñ después de n,
+// acentos como diferencia secundaria) usando claves de ordenamiento precalculadas
+func buildSortIndex(version uint64, spec SortSpec, allContactos []models.Contacto) *sortIndex {
+	collator := collate.New(language.Spanish)
+	var buf collate.Buffer
+
+	// Claves binarias por campo de texto; la clave cliente se compara como número
+	keys := make([][][]byte, len(spec))
+	for f, field := range spec {
+		if field.Field == querylang.FieldClave {
+			continue
+		}
+		keys[f] = make([][]byte, len(allContactos))
+		for i, contacto := range allContactos {
+			keys[f][i] = append([]byte(nil), collator.KeyFromString(&buf, contactoFieldValue(contacto, field.Field))...)
+			buf.Reset()
+		}
+	}
+
+	order := make([]int32, len(allContactos))
+	for i := range order {
+		order[i] = int32(i)
+	}
+	sort.Slice(order, func(a, b int) bool {
+		i, j := order[a], order[b]
+		for f, field := range spec {
+			var cmp int
+			if field.Field == querylang.FieldClave {
+				cmp = compareInts(allContactos[i].ClaveCliente, allContactos[j].ClaveCliente)
+			} else {
+				cmp = bytes.Compare(keys[f][i], keys[f][j])
+			}
+			if cmp != 0 {
+				return (cmp < 0) != field.Desc
+			}
+		}
+		return allContactos[i].ClaveCliente < allContactos[j].ClaveCliente
+	})
+
+	rank := make(map[int]int32, len(allContactos))
+	for position, i := range order {
+		rank[allContactos[i].ClaveCliente] = int32(position)
+	}
+	return &sortIndex{version: version, spec: spec.String(), rank: rank}
+}
+
+// sortByRank ordena una copia del resultado según el orden completo; los contactos que no
+// estén en el índice (agregados durante la consulta) van al final por clave
+func sortByRank(result *searchResult, rank map[int]int32) *searchResult {
+	n := len(result.contactos)
+	ranks := make([]int64, n)
+	perm := make([]int, n)
+	for i, contacto := range result.contactos {
+		perm[i] = i
+		if r, ok := rank[contacto.ClaveCliente]; ok {
+			ranks[i] = int64(r)
+		} else {
+			ranks[i] = math.MaxInt32 + int64(contacto.ClaveCliente)
+		}
+	}
+	sort.Slice(perm, func(a, b int) bool { return ranks[perm[a]] < ranks[perm[b]] })
+
+	sorted := &searchResult{
+		contactos: make([]models.Contacto, n),
+		highlight: result.highlight,
+		partial:   result.partial,
+	}
+	if result.scores != nil {
+		sorted.scores = make([]float64, n)
+	}
+	for i, p := range perm {
+		sorted.contactos[i] = result.contactos[p]
+		if result.scores != nil {
+			sorted.scores[i] = result.scores[p]
+		}
+	}
+	return sorted
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,69 @@
+// services/contacto_service_suggestions.go
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"contactos-api/models"
+	"contactos-api/validators"
+)
+
+// suggestFix propone cómo corregir un error de carga, con el valor corregido cuando se puede
+// deducir; rules son las reglas activas, las mismas con que se validó la fila
+func suggestFix(rowError models.RowError, rules validators.ValidatorConfig) string {
+	value := strings.TrimSpace(rowError.Value)
+
+	switch classifyRowError(rowError) {
+	case ErrorTypeEstructura:
+		return "Complete las 4 columnas: ClaveCliente, Nombre, Correo y TelefonoContacto"
+	case ErrorTypeVacio:
+		return "Capture los valores faltantes; todas las columnas son obligatorias"
+	case ErrorTypeDuplicado:
+		return "Asigne una clave que no exista o elimine la fila repetida"
+	case ErrorTypeLongitud:
+		return suggestTelefono(value, rules.TelefonoDigitos)
+	case ErrorTypeNumerico:
+		if rowError.Field == "telefonoContacto" {
+			return suggestTelefono(value, rules.TelefonoDigitos)
+		}
+		if digitos := onlyDigits(value); digitos != "" && digitos != value {
+			return fmt.Sprintf("Use solo dígitos: %s", strings.TrimLeft(digitos, "0"))
+		}
+		return "Use un número entero mayor a cero"
+	case ErrorTypeCorreo:
+		if strings.Count(value, "@") == 0 {
+			for _, dominio := range rules.DominiosCorreo {
+				if usuario, ok := strings.CutSuffix(strings.ToLower(value), dominio); ok && usuario != "" {
+					return fmt.Sprintf("Agregue la @ antes del dominio: %s@%s", value[:len(usuario)], dominio)
+				}
+			}
+		}
+		return "Use el formato usuario@dominio.com"
+	}
+
+	return "Revise el valor de la celda"
+}
+
+// suggestTelefono propone cómo dejar el teléfono con la cantidad de dígitos de la regla
+func suggestTelefono(value string, telefonoDigitos int) string {
+	digitos := onlyDigits(value)
+	switch {
+	case len(digitos) == telefonoDigitos && digitos != value:
+		return fmt.Sprintf("Quite los caracteres que no son dígitos: %s", digitos)
+	case len(digitos) == telefonoDigitos+2 && strings.HasPrefix(digitos, "52"):
+		return fmt.Sprintf("Quite la lada internacional: %s", digitos[2:])
+	default:
+		return fmt.Sprintf("Capture exactamente %d dígitos (tiene %d)", telefonoDigitos, len(digitos))
+	}
+}
+
+// onlyDigits conserva solo los dígitos de un valor
+func onlyDigits(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, value)
+}
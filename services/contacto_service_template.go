@@ -0,0 +1,13 @@
+// services/contacto_service_template.go
+package services
+
+import (
+	"io"
+
+	"contactos-api/exporters"
+)
+
+// WriteImportTemplate escribe la plantilla de importación con las reglas del validador activo
+func (s *ContactoService) WriteImportTemplate(w io.Writer) error {
+	return exporters.WriteImportTemplate(w, s.validator.Config())
+}
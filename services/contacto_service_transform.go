@@ -0,0 +1,486 @@
+// services/contacto_service_transform.go
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"contactos-api/models"
+	"contactos-api/repositories"
+)
+
+// Opciones de una transformación
+const (
+	TransformCaseLower = "lower"
+	TransformCaseUpper = "upper"
+
+	TransformOnInvalidAbort = "abort" // No guarda nada si algún resultado es inválido
+	TransformOnInvalidOmit  = "omit"  // Guarda solo los resultados válidos
+)
+
+const (
+	defaultTransformSamples = 20
+	maxTransformSamples     = 200
+	// undoTTL tiempo durante el cual se puede deshacer una transformación
+	undoTTL = time.Hour
+	// maxStoredUndos entradas de deshacer guardadas a la vez (las más viejas se descartan)
+	maxStoredUndos = 5
+)
+
+var (
+	// ErrUndoNotFound la entrada no existe o ya expiró
+	ErrUndoNotFound = errors.New("entrada de deshacer no encontrada o expirada")
+	// ErrUndoStale los datos cambiaron después de la transformación
+	ErrUndoStale = errors.New("los datos cambiaron después de la transformación; ya no se puede deshacer")
+)
+
+// transformUndo valores previos de los contactos transformados; solo se puede aplicar mientras
+// los datos sigan en la versión que dejó la transformación
+type transformUndo struct {
+	positions []int             // Posición de cada contacto en la lista
+	before    []models.Contacto // Contacto original en esa posición
+	version   uint64
+	createdAt time.Time
+	expiresAt time.Time
+}
+
+// undoStore guarda en memoria las entradas de deshacer de las transformaciones
+type undoStore struct {
+	entries map[string]*transformUndo
+	mu      sync.Mutex
+}
+
+func newUndoStore() *undoStore {
+	return &undoStore{entries: make(map[string]*transformUndo)}
+}
+
+// add guarda una entrada y retorna su id
+func (us *undoStore) add(entry *transformUndo) (string, error) {
+	id, err := randomHex(16)
+	if err != nil {
+		return "", fmt.Errorf("error generando id: %w", err)
+	}
+
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	now := time.Now()
+	for key, stored := range us.entries {
+		if now.After(stored.expiresAt) {
+			delete(us.entries, key)
+		}
+	}
+	if len(us.entries) >= maxStoredUndos {
+		var oldestID string
+		var oldest time.Time
+		for key, stored := range us.entries {
+			if oldestID == "" || stored.createdAt.Before(oldest) {
+				oldestID, oldest = key, stored.createdAt
+			}
+		}
+		delete(us.entries, oldestID)
+	}
+	us.entries[id] = entry
+	return id, nil
+}
+
+// take retira una entrada vigente (solo se puede deshacer una vez)
+func (us *undoStore) take(id string) (*transformUndo, bool) {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	entry, ok := us.entries[id]
+	if !ok || time.Now().After(entry.expiresAt) {
+		delete(us.entries, id)
+		return nil, false
+	}
+	delete(us.entries, id)
+	return entry, true
+}
+
+// fieldTransformer reemplazo compilado sobre un campo
+type fieldTransformer struct {
+	field      string
+	re         *regexp.Regexp
+	replace    string
+	caseChange string
+}
+
+// apply reemplaza cada coincidencia expandiendo $1/${nombre} y aplicando el cambio de mayúsculas
+func (t *fieldTransformer) apply(value string) (string, bool) {
+	matches := t.re.FindAllStringSubmatchIndex(value, -1)
+	if len(matches) == 0 {
+		return value, false
+	}
+
+	var result []byte
+	last := 0
+	for _, match := range matches {
+		result = append(result, value[last:match[0]]...)
+		expanded := t.re.ExpandString(nil, t.replace, value, match)
+		switch t.caseChange {
+		case TransformCaseLower:
+			expanded = []byte(strings.ToLower(string(expanded)))
+		case TransformCaseUpper:
+			expanded = []byte(strings.ToUpper(string(expanded)))
+		}
+		result = append(result, expanded...)
+		last = match[1]
+	}
+	result = append(result, value[last:]...)
+	return string(result), true
+}
+
+// fieldValue valor en texto del campo transformado
+func (t *fieldTransformer) fieldValue(contacto models.Contacto) string {
+	switch t.field {
+	case "claveCliente":
+		return strconv.Itoa(contacto.ClaveCliente)
+	case "nombre":
+		return contacto.Nombre
+	case "correo":
+		return contacto.Correo
+	default:
+		return contacto.TelefonoContacto
+	}
+}
+
+// withValue copia del contacto con el nuevo valor (la clave ya validada como número)
+func (t *fieldTransformer) withValue(contacto models.Contacto, value string) models.Contacto {
+	switch t.field {
+	case "claveCliente":
+		contacto.ClaveCliente, _ = strconv.Atoi(strings.TrimSpace(value))
+	case "nombre":
+		contacto.Nombre = value
+	case "correo":
+		contacto.Correo = value
+	default:
+		contacto.TelefonoContacto = value
+	}
+	return contacto
+}
+
+// validateTransformRequest valida campo, patrón y opciones
+func validateTransformRequest(request *models.TransformRequest) (*fieldTransformer, []models.ErrorResponse) {
+	var errores []models.ErrorResponse
+
+	if !isValidatedField(request.Field) {
+		errores = append(errores, models.ErrorResponse{
+			Campo:   "field",
+			Mensaje: "Campo inválido. Use claveCliente, nombre, correo o telefonoContacto",
+		})
+	}
+
+	var re *regexp.Regexp
+	if request.Find == "" {
+		errores = append(errores, models.ErrorResponse{Campo: "find", Mensaje: "El patrón es requerido"})
+	} else {
+		var err error
+		if re, err = CompileSearchRegex(request.Find); err != nil {
+			errores = append(errores, models.ErrorResponse{Campo: "find", Mensaje: err.Error()})
+		}
+	}
+
+	switch request.Case {
+	case "", TransformCaseLower, TransformCaseUpper:
+	default:
+		errores = append(errores, models.ErrorResponse{Campo: "case", Mensaje: "Use lower o upper"})
+	}
+	switch request.OnInvalid {
+	case "", TransformOnInvalidAbort, TransformOnInvalidOmit:
+	default:
+		errores = append(errores, models.ErrorResponse{Campo: "onInvalid", Mensaje: "Use abort u omit"})
+	}
+
+	if len(errores) > 0 {
+		return nil, errores
+	}
+	return &fieldTransformer{field: request.Field, re: re, replace: request.Replace, caseChange: request.Case}, nil
+}
+
+// TransformContactos aplica un buscar y reemplazar sobre un campo de los contactos que cumplen
+// el filtro. En dry-run solo calcula los cambios; si no, los guarda en una sola escritura y
+// registra una entrada para deshacerlos.
+func (s *ContactoService) TransformContactos(request *models.TransformRequest, filter SearchOptions, dryRun bool) (*models.TransformResult, []models.ErrorResponse, error) {
+	transformer, errores := validateTransformRequest(request)
+	if len(errores) > 0 {
+		return nil, errores, nil
+	}
+	bulk, ok := s.repo.(repositories.BulkContactoRepository)
+	if !ok {
+		return nil, nil, fmt.Errorf("transformación no disponible")
+	}
+
+	samples := request.Samples
+	if samples <= 0 {
+		samples = defaultTransformSamples
+	}
+	if samples > maxTransformSamples {
+		samples = maxTransformSamples
+	}
+
+	s.loadMu.Lock()
+	defer s.loadMu.Unlock()
+
+	subset, err := s.filterContactos(filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	if subset.partial != "" {
+		return nil, []models.ErrorResponse{{
+			Campo:   "filter",
+			Mensaje: fmt.Sprintf("El filtro se detuvo por presupuesto (%s); use un filtro más específico", subset.partial),
+		}}, nil
+	}
+
+	allContactos, err := s.repo.GetAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error obteniendo contactos: %w", err)
+	}
+	positions := make(map[int]int, len(allContactos))
+	claves := make(map[int]int, len(allContactos)) // Clave -> contactos que la tendrán
+	for i, contacto := range allContactos {
+		positions[contacto.ClaveCliente] = i
+		claves[contacto.ClaveCliente]++
+	}
+
+	result := &models.TransformResult{
+		Field:    request.Field,
+		Find:     request.Find,
+		Replace:  request.Replace,
+		Case:     request.Case,
+		DryRun:   dryRun,
+		Scanned:  len(subset.contactos),
+		Changes:  []models.TransformChange{},
+		Rejected: []models.TransformChange{},
+	}
+
+	type pendingChange struct {
+		position int
+		after    models.Contacto
+		change   models.TransformChange
+	}
+	var pending []pendingChange
+
+	for _, contacto := range subset.contactos {
+		before := transformer.fieldValue(contacto)
+		after, matched := transformer.apply(before)
+		if !matched {
+			continue
+		}
+		result.Matched++
+		if after == before {
+			continue
+		}
+
+		change := models.TransformChange{ClaveCliente: contacto.ClaveCliente, Before: before, After: after}
+		if err := s.validator.ValidarCampo(request.Field, after); err != nil {
+			change.Error = err.Mensaje
+		}
+		updated := transformer.withValue(contacto, after)
+		if change.Error == "" && updated.ClaveCliente != contacto.ClaveCliente {
+			claves[contacto.ClaveCliente]--
+			claves[updated.ClaveCliente]++
+		}
+		pending = append(pending, pendingChange{position: positions[contacto.ClaveCliente], after: updated, change: change})
+	}
+
+	// Las claves nuevas no pueden repetir otra existente ni otra transformada. Un cambio rechazado
+	// conserva su clave original, que puede chocar con otro cambio ya aceptado: se repite la
+	// revisión hasta que el conjunto final de claves no tenga repetidas.
+	if request.Field == "claveCliente" {
+		for rejected := true; rejected; {
+			rejected = false
+			for i := range pending {
+				p := &pending[i]
+				if p.change.Error != "" || claves[p.after.ClaveCliente] <= 1 {
+					continue
+				}
+				p.change.Error = fmt.Sprintf("La clave cliente %d quedaría repetida", p.after.ClaveCliente)
+				claves[p.after.ClaveCliente]--
+				claves[p.change.ClaveCliente]++
+				rejected = true
+			}
+		}
+	}
+
+	valid := pending[:0]
+	for _, p := range pending {
+		if p.change.Error != "" {
+			result.Invalid++
+			if len(result.Rejected) < samples {
+				result.Rejected = append(result.Rejected, p.change)
+			}
+			continue
+		}
+		result.Changed++
+		if len(result.Changes) < samples {
+			result.Changes = append(result.Changes, p.change)
+		}
+		valid = append(valid, p)
+	}
+
+	if dryRun || len(valid) == 0 {
+		return result, nil, nil
+	}
+	if result.Invalid > 0 && request.OnInvalid != TransformOnInvalidOmit {
+		errores := []models.ErrorResponse{{
+			Campo:   request.Field,
+			Mensaje: fmt.Sprintf("%d valores resultantes no son válidos; no se guardó nada. Revise la vista previa o use onInvalid=omit", result.Invalid),
+		}}
+		for _, rejected := range result.Rejected {
+			errores = append(errores, models.ErrorResponse{
+				Campo:   request.Field,
+				Mensaje: fmt.Sprintf("Clave %d: '%s' -> '%s': %s", rejected.ClaveCliente, rejected.Before, rejected.After, rejected.Error),
+			})
+		}
+		return nil, errores, nil
+	}
+
+	// Una sola escritura con la lista completa; las filas inválidas de la carga se conservan
+	updated := append([]models.Contacto(nil), allContactos...)
+	undo := &transformUndo{positions: make([]int, len(valid)), before: make([]models.Contacto, len(valid))}
+	for i, p := range valid {
+		undo.positions[i] = p.position
+		undo.before[i] = allContactos[p.position]
+		updated[p.position] = p.after
+	}
+	if err := s.replaceContactos(bulk, updated); err != nil {
+		return nil, nil, fmt.Errorf("error guardando transformación: %w", err)
+	}
+	s.publishTransform(undo.before, updated, undo.positions)
+
+	undo.version = s.version.Load()
+	undo.createdAt = time.Now()
+	undo.expiresAt = undo.createdAt.Add(undoTTL)
+	id, err := s.undos.add(undo)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result.Applied = len(valid)
+	result.UndoID = id
+	result.UndoExpiresAt = undo.expiresAt.Format(time.RFC3339)
+	return result, nil, nil
+}
+
+// UndoTransform restaura los valores previos a una transformación si los datos no cambiaron desde entonces
+func (s *ContactoService) UndoTransform(id string) (*models.TransformUndoResult, error) {
+	bulk, ok := s.repo.(repositories.BulkContactoRepository)
+	if !ok {
+		return nil, fmt.Errorf("transformación no disponible")
+	}
+
+	s.loadMu.Lock()
+	defer s.loadMu.Unlock()
+
+	undo, ok := s.undos.take(id)
+	if !ok {
+		return nil, ErrUndoNotFound
+	}
+	if undo.version != s.version.Load() {
+		return nil, ErrUndoStale
+	}
+
+	allContactos, err := s.repo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo contactos: %w", err)
+	}
+	restored := append([]models.Contacto(nil), allContactos...)
+	transformed := make([]models.Contacto, len(undo.positions))
+	for i, position := range undo.positions {
+		transformed[i] = restored[position]
+		restored[position] = undo.before[i]
+	}
+	if err := s.replaceContactos(bulk, restored); err != nil {
+		return nil, fmt.Errorf("error deshaciendo transformación: %w", err)
+	}
+	s.publishTransform(transformed, restored, undo.positions)
+
+	return &models.TransformUndoResult{UndoID: id, Restored: len(undo.positions)}, nil
+}
+
+// replaceContactos guarda la lista completa conservando los errores de carga y la fila y el
+// archivo de origen de cada posición: transformar y deshacer cambian contactos en su lugar,
+// aunque cambie la clave (requiere loadMu tomado)
+func (s *ContactoService) replaceContactos(bulk repositories.BulkContactoRepository, contactos []models.Contacto) error {
+	sourceRows, sources, err := s.currentOrigins(len(contactos))
+	if err != nil {
+		return err
+	}
+
+	err = bulk.ReplaceAll(&repositories.ParseResult{
+		Contactos:       contactos,
+		LoadErrors:      s.repo.GetLoadErrors(),
+		InvalidRowsData: s.repo.GetInvalidRowsData(),
+		TotalRows:       len(contactos) + len(s.repo.GetInvalidRowsData()),
+		SourceRows:      sourceRows,
+		Sources:         sources,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.stats.reset(contactos)
+	s.dataChanged()
+	return nil
+}
+
+// currentOrigins fila y archivo de origen de cada posición de la lista actual, los que el
+// repositorio recuerde, si la lista sigue teniendo n contactos
+func (s *ContactoService) currentOrigins(n int) ([]int, []string, error) {
+	actuales, err := s.repo.GetAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error obteniendo contactos: %w", err)
+	}
+	if len(actuales) != n {
+		return nil, nil, nil
+	}
+
+	var sourceRows []int
+	if tracker, ok := s.repo.(repositories.SourceRowTracker); ok {
+		rows := tracker.SourceRows()
+		sourceRows = make([]int, n)
+		for i, contacto := range actuales {
+			sourceRows[i] = rows[contacto.ClaveCliente]
+		}
+	}
+
+	var sources []string
+	if tracker, ok := s.repo.(repositories.SourceTracker); ok {
+		origenes := tracker.Sources()
+		sources = make([]string, n)
+		for i, contacto := range actuales {
+			sources[i] = origenes[contacto.ClaveCliente]
+		}
+	}
+	return sourceRows, sources, nil
+}
+
+// publishTransform emite los eventos de los contactos modificados; un cambio de clave
+// equivale a borrar la clave anterior y crear la nueva
+func (s *ContactoService) publishTransform(before []models.Contacto, after []models.Contacto, positions []int) {
+	var updated, deleted, created []int
+	for i, position := range positions {
+		anterior, nuevo := before[i], after[position]
+		if anterior.ClaveCliente == nuevo.ClaveCliente {
+			updated = append(updated, nuevo.ClaveCliente)
+			continue
+		}
+		deleted = append(deleted, anterior.ClaveCliente)
+		created = append(created, nuevo.ClaveCliente)
+	}
+
+	if len(updated) > 0 {
+		s.events.publish(models.EventUpdated, EventSourceTransform, updated)
+	}
+	if len(deleted) > 0 {
+		s.events.publish(models.EventDeleted, EventSourceTransform, deleted)
+		s.events.publish(models.EventCreated, EventSourceTransform, created)
+	}
+}
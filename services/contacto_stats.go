@@ -0,0 +1,253 @@
+// services/contacto_stats.go
+package services
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"contactos-api/models"
+	"contactos-api/validators"
+)
+
+const (
+	// topDominiosLimit cantidad de dominios incluidos en las estadísticas
+	topDominiosLimit = 5
+	// areaDesconocida agrupa teléfonos sin clave de área reconocible
+	areaDesconocida = "desconocida"
+)
+
+// statsCampos campos de Contacto incluidos en las estadísticas por campo
+var statsCampos = []string{"claveCliente", "nombre", "correo", "telefonoContacto"}
+
+// areasDosDigitos claves LADA de 2 dígitos (CDMX, Guadalajara, Monterrey)
+var areasDosDigitos = map[string]bool{"55": true, "56": true, "33": true, "81": true}
+
+// statsTracker mantiene las estadísticas de contactos de forma incremental
+type statsTracker struct {
+	total    int
+	valores  map[string]map[string]int // campo -> valor -> ocurrencias
+	vacios   map[string]int
+	dominios map[string]int
+	areas    map[string]int
+
+	// telefonoDigitos longitud de teléfono de la regla activa; otros teléfonos no tienen área
+	telefonoDigitos int
+
+	mu sync.RWMutex
+}
+
+// newStatsTracker crea un tracker vacío
+func newStatsTracker() *statsTracker {
+	t := &statsTracker{telefonoDigitos: validators.DefaultTelefonoDigitos}
+	t.reset(nil)
+	return t
+}
+
+// setTelefonoDigitos cambia la longitud de teléfono de la regla activa y recalcula las áreas
+func (t *statsTracker) setTelefonoDigitos(digitos int, contactos []models.Contacto) {
+	t.mu.Lock()
+	t.telefonoDigitos = digitos
+	t.mu.Unlock()
+	t.reset(contactos)
+}
+
+// reset recalcula todas las estadísticas desde cero (carga y recarga)
+func (t *statsTracker) reset(contactos []models.Contacto) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.total = 0
+	t.valores = make(map[string]map[string]int, len(statsCampos))
+	for _, campo := range statsCampos {
+		t.valores[campo] = make(map[string]int)
+	}
+	t.vacios = make(map[string]int, len(statsCampos))
+	t.dominios = make(map[string]int)
+	t.areas = make(map[string]int)
+
+	for i := range contactos {
+		t.add(&contactos[i], 1)
+	}
+}
+
+// apply registra una mutación: before nil = alta, after nil = baja
+func (t *statsTracker) apply(before, after *models.Contacto) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if before != nil {
+		t.add(before, -1)
+	}
+	if after != nil {
+		t.add(after, 1)
+	}
+}
+
+// add suma (delta=1) o resta (delta=-1) un contacto de los acumulados
+func (t *statsTracker) add(contacto *models.Contacto, delta int) {
+	t.total += delta
+
+	for campo, valor := range statsValores(contacto) {
+		if valor == "" {
+			t.vacios[campo] += delta
+			continue
+		}
+		incrementar(t.valores[campo], valor, delta)
+	}
+
+	if dominio := dominioDeCorreo(contacto.Correo); dominio != "" {
+		incrementar(t.dominios, dominio, delta)
+	}
+
+	if strings.TrimSpace(contacto.TelefonoContacto) != "" {
+		incrementar(t.areas, areaTelefonica(contacto.TelefonoContacto, t.telefonoDigitos), delta)
+	}
+}
+
+// snapshot genera las estadísticas tipadas a partir de los acumulados
+func (t *statsTracker) snapshot(totalErrores, totalInvalidos int) *models.ContactoStats {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	stats := &models.ContactoStats{
+		Total:                t.total,
+		TotalErrores:         totalErrores,
+		TotalInvalidos:       totalInvalidos,
+		TotalDominios:        len(t.dominios),
+		TopDominios:          []models.DominioStats{},
+		AreasTelefonicas:     []models.AreaStats{},
+		EstadisticasPorCampo: make(map[string]models.FieldStats, len(statsCampos)),
+		Timestamp:            time.Now().Format("2006-01-02 15:04:05"),
+	}
+
+	if t.total+totalInvalidos > 0 {
+		stats.PorcentajeValidos = float64(t.total) / float64(t.total+totalInvalidos) * 100
+	}
+
+	for _, campo := range statsCampos {
+		fieldStats := models.FieldStats{
+			ValoresUnicos: len(t.valores[campo]),
+			ValoresVacios: t.vacios[campo],
+		}
+		if t.total > 0 {
+			fieldStats.Completitud = float64(t.total-t.vacios[campo]) / float64(t.total) * 100
+		}
+		stats.EstadisticasPorCampo[campo] = fieldStats
+	}
+
+	// Dominios ordenados por frecuencia
+	conCorreo := 0
+	for _, count := range t.dominios {
+		conCorreo += count
+	}
+	for _, entry := range sortedCounts(t.dominios, topDominiosLimit) {
+		stats.TopDominios = append(stats.TopDominios, models.DominioStats{
+			Dominio:    entry.valor,
+			Count:      entry.count,
+			Porcentaje: porcentaje(entry.count, conCorreo),
+		})
+	}
+
+	// Distribución completa de claves de área
+	conTelefono := 0
+	for _, count := range t.areas {
+		conTelefono += count
+	}
+	for _, entry := range sortedCounts(t.areas, 0) {
+		stats.AreasTelefonicas = append(stats.AreasTelefonicas, models.AreaStats{
+			Area:       entry.valor,
+			Count:      entry.count,
+			Porcentaje: porcentaje(entry.count, conTelefono),
+		})
+	}
+
+	return stats
+}
+
+// 🛠️ FUNCIONES AUXILIARES
+
+type valorCount struct {
+	valor string
+	count int
+}
+
+// sortedCounts ordena un mapa de conteos de mayor a menor (limit 0 = sin límite)
+func sortedCounts(counts map[string]int, limit int) []valorCount {
+	entries := make([]valorCount, 0, len(counts))
+	for valor, count := range counts {
+		entries = append(entries, valorCount{valor: valor, count: count})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].count != entries[j].count {
+			return entries[i].count > entries[j].count
+		}
+		return entries[i].valor < entries[j].valor
+	})
+
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries
+}
+
+// incrementar aplica delta a un conteo y elimina la entrada cuando llega a cero
+func incrementar(counts map[string]int, key string, delta int) {
+	counts[key] += delta
+	if counts[key] <= 0 {
+		delete(counts, key)
+	}
+}
+
+func porcentaje(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(count) / float64(total) * 100
+}
+
+// statsValores extrae los valores normalizados de cada campo del contacto
+func statsValores(contacto *models.Contacto) map[string]string {
+	clave := ""
+	if contacto.ClaveCliente > 0 {
+		clave = strconv.Itoa(contacto.ClaveCliente)
+	}
+
+	return map[string]string{
+		"claveCliente":     clave,
+		"nombre":           strings.TrimSpace(contacto.Nombre),
+		"correo":           strings.ToLower(strings.TrimSpace(contacto.Correo)),
+		"telefonoContacto": strings.TrimSpace(contacto.TelefonoContacto),
+	}
+}
+
+// dominioDeCorreo retorna el dominio en minúsculas o "" si el correo no es válido
+func dominioDeCorreo(correo string) string {
+	parts := strings.Split(strings.TrimSpace(correo), "@")
+	if len(parts) != 2 || parts[1] == "" {
+		return ""
+	}
+	return strings.ToLower(parts[1])
+}
+
+// areaTelefonica obtiene la clave LADA (los primeros 2 o 3 dígitos) de un teléfono con la
+// cantidad de dígitos de la regla activa
+func areaTelefonica(telefono string, digitos int) string {
+	telefono = strings.TrimSpace(telefono)
+	if len(telefono) != digitos || len(telefono) < 3 {
+		return areaDesconocida
+	}
+	for _, char := range telefono {
+		if char < '0' || char > '9' {
+			return areaDesconocida
+		}
+	}
+
+	if areasDosDigitos[telefono[:2]] {
+		return telefono[:2]
+	}
+	return telefono[:3]
+}
@@ -0,0 +1,186 @@
+// services/events.go
+package services
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"contactos-api/models"
+)
+
+const (
+	// eventBufferSize eventos recientes conservados para reanudar con Last-Event-ID
+	eventBufferSize = 1000
+	// subscriberBufferSize eventos pendientes por suscriptor antes de desconectarlo
+	subscriberBufferSize = 64
+	// maxEventClaves claves incluidas en un evento (recargas grandes se recortan)
+	maxEventClaves = 1000
+)
+
+// Orígenes de un evento
+const (
+	EventSourceAPI       = "api"
+	EventSourceImport    = "import"
+	EventSourceReload    = "reload"
+	EventSourceTransform = "transform"
+)
+
+// EventSubscription suscripción al stream de cambios
+type EventSubscription struct {
+	// Backlog eventos posteriores al Last-Event-ID solicitado
+	Backlog []models.ChangeEvent
+	// Missed indica que el Last-Event-ID ya no está en el buffer y hubo eventos perdidos
+	Missed bool
+	// Events recibe los eventos nuevos; se cierra si el suscriptor no alcanza a consumirlos
+	Events <-chan models.ChangeEvent
+
+	events chan models.ChangeEvent
+	broker *eventBroker
+}
+
+// Close cancela la suscripción
+func (sub *EventSubscription) Close() {
+	sub.broker.unsubscribe(sub)
+}
+
+// eventBroker reparte los eventos a los suscriptores y guarda los más recientes
+type eventBroker struct {
+	mu          sync.Mutex
+	lastID      uint64
+	buffer      []models.ChangeEvent
+	subscribers map[*EventSubscription]struct{}
+	// listeners reciben todos los eventos sin riesgo de desconexión (p. ej. webhooks)
+	listeners []func(models.ChangeEvent)
+}
+
+func newEventBroker() *eventBroker {
+	return &eventBroker{
+		buffer:      make([]models.ChangeEvent, 0, eventBufferSize),
+		subscribers: make(map[*EventSubscription]struct{}),
+	}
+}
+
+// publish registra un evento y lo envía a los suscriptores
+func (b *eventBroker) publish(eventType, source string, claves []int) {
+	event := models.ChangeEvent{
+		Type:      eventType,
+		Source:    source,
+		Claves:    claves,
+		Count:     len(claves),
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+	if event.Claves == nil {
+		event.Claves = []int{}
+	}
+	if len(event.Claves) > maxEventClaves {
+		event.Claves = event.Claves[:maxEventClaves]
+		event.Truncated = true
+	}
+
+	b.mu.Lock()
+	b.lastID++
+	event.ID = b.lastID
+
+	if len(b.buffer) == eventBufferSize {
+		copy(b.buffer, b.buffer[1:])
+		b.buffer = b.buffer[:eventBufferSize-1]
+	}
+	b.buffer = append(b.buffer, event)
+
+	for sub := range b.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			// Suscriptor lento: se desconecta y podrá reanudar con Last-Event-ID
+			delete(b.subscribers, sub)
+			close(sub.events)
+		}
+	}
+	listeners := b.listeners
+	b.mu.Unlock()
+
+	for _, listener := range listeners {
+		listener(event)
+	}
+}
+
+// addListener registra una función que recibe cada evento publicado
+func (b *eventBroker) addListener(listener func(models.ChangeEvent)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.listeners = append(b.listeners, listener)
+}
+
+// subscribe crea una suscripción; con resume retorna los eventos posteriores a lastEventID
+func (b *eventBroker) subscribe(lastEventID uint64, resume bool) *EventSubscription {
+	events := make(chan models.ChangeEvent, subscriberBufferSize)
+	sub := &EventSubscription{Events: events, events: events, broker: b}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if resume {
+		switch {
+		case lastEventID > b.lastID:
+			// Id desconocido (p. ej. el servidor se reinició)
+			sub.Missed = true
+		case len(b.buffer) > 0 && lastEventID+1 < b.buffer[0].ID:
+			// Los eventos intermedios ya salieron del buffer
+			sub.Missed = true
+		default:
+			for _, event := range b.buffer {
+				if event.ID > lastEventID {
+					sub.Backlog = append(sub.Backlog, event)
+				}
+			}
+		}
+	}
+
+	b.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// unsubscribe retira una suscripción si sigue activa
+func (b *eventBroker) unsubscribe(sub *EventSubscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscribers[sub]; ok {
+		delete(b.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// SubscribeEvents se suscribe al stream de cambios de contactos
+func (s *ContactoService) SubscribeEvents(lastEventID uint64, resume bool) *EventSubscription {
+	return s.events.subscribe(lastEventID, resume)
+}
+
+// publishDiff publica los eventos de una recarga o reemplazo completo
+func (s *ContactoService) publishDiff(source string, diff *models.ValidationDiff) {
+	claves := make([]int, 0, diff.Counts.Added+diff.Counts.Removed+diff.Counts.Changed)
+	for _, contacto := range diff.AddedContacts {
+		claves = append(claves, contacto.ClaveCliente)
+	}
+	for _, contacto := range diff.RemovedContacts {
+		claves = append(claves, contacto.ClaveCliente)
+	}
+	for _, cambio := range diff.ChangedContacts {
+		claves = append(claves, cambio.ClaveCliente)
+	}
+	s.events.publish(models.EventReloaded, source, claves)
+
+	if diff.Counts.NewlyInvalid+diff.Counts.Fixed == 0 {
+		return
+	}
+	filas := make([]int, 0, diff.Counts.NewlyInvalid+diff.Counts.Fixed)
+	for _, rows := range [][]models.RowData{diff.NewlyInvalidRows, diff.FixedRows} {
+		for _, rowData := range rows {
+			if clave, err := strconv.Atoi(rowData.ClaveCliente); err == nil {
+				filas = append(filas, clave)
+			}
+		}
+	}
+	s.events.publish(models.EventValidationChanged, source, filas)
+}
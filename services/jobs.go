@@ -0,0 +1,294 @@
+// services/jobs.go
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"contactos-api/models"
+	"contactos-api/repositories"
+)
+
+const (
+	// Tipos de trabajo
+	JobTypeImport = "import"
+	JobTypeReload = "reload"
+
+	// JobPhaseApplying el archivo ya se procesó y se están guardando los cambios; desde aquí
+	// el trabajo ya no se puede cancelar
+	JobPhaseApplying = "aplicando"
+
+	// jobRetention tiempo que se conservan los trabajos terminados
+	jobRetention = time.Hour
+	// maxFinishedJobs trabajos terminados conservados como máximo
+	maxFinishedJobs = 50
+)
+
+var (
+	// ErrJobNotFound el trabajo no existe o ya fue descartado
+	ErrJobNotFound = errors.New("trabajo no encontrado")
+	// ErrJobNotFinished el trabajo aún no tiene resultado
+	ErrJobNotFinished = errors.New("el trabajo aún no ha terminado")
+	// ErrJobFinished el trabajo ya terminó y no se puede cancelar
+	ErrJobFinished = errors.New("el trabajo ya terminó")
+	// ErrJobFailed el trabajo terminó sin resultado (falló o fue cancelado)
+	ErrJobFailed = errors.New("el trabajo no produjo resultado")
+)
+
+// backgroundJob estado interno de un trabajo
+type backgroundJob struct {
+	job       models.Job
+	startedAt time.Time
+	cancel    context.CancelFunc
+	result    interface{}
+	mu        sync.Mutex
+}
+
+// snapshot retorna una copia del estado con progreso y ETA calculados
+func (b *backgroundJob) snapshot() models.Job {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	job := b.job
+	if job.TotalRows > 0 {
+		job.Progress = float64(job.RowsProcessed) / float64(job.TotalRows) * 100
+		if job.Status == models.JobStatusRunning && job.RowsProcessed > 0 && job.RowsProcessed < job.TotalRows {
+			elapsed := time.Since(b.startedAt).Seconds()
+			job.ETASeconds = elapsed / float64(job.RowsProcessed) * float64(job.TotalRows-job.RowsProcessed)
+		}
+	}
+	if job.Status == models.JobStatusCompleted {
+		job.Progress = 100
+	}
+	return job
+}
+
+// updateProgress registra la fase y el avance reportados por el parser; las demás fases
+// no traen conteos y conservan los últimos
+func (b *backgroundJob) updateProgress(progress repositories.ParseProgress) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.job.Phase = progress.Phase
+	if progress.Phase != repositories.ParsePhaseParsing {
+		return
+	}
+	b.job.RowsProcessed = progress.Processed
+	b.job.ValidRows = progress.Valid
+	b.job.InvalidRows = progress.Invalid
+	if progress.Total > 0 {
+		b.job.TotalRows = progress.Total
+	}
+	if b.job.TotalRows < b.job.RowsProcessed {
+		b.job.TotalRows = b.job.RowsProcessed
+	}
+}
+
+// jobManager ejecuta trabajos en segundo plano independientes del request que los inicia
+type jobManager struct {
+	jobs map[string]*backgroundJob
+	mu   sync.RWMutex
+}
+
+func newJobManager() *jobManager {
+	return &jobManager{jobs: make(map[string]*backgroundJob)}
+}
+
+// start registra y lanza un trabajo; run recibe el contexto cancelable y el job para reportar avance
+func (m *jobManager) start(job models.Job, run func(ctx context.Context, b *backgroundJob) (interface{}, error)) (*models.Job, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("error generando id de trabajo: %w", err)
+	}
+
+	job.ID = hex.EncodeToString(buf)
+	job.Status = models.JobStatusPending
+	job.CreatedAt = time.Now().Format(time.RFC3339)
+
+	// El contexto no depende del request: el trabajo sobrevive a la conexión
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &backgroundJob{job: job, cancel: cancel}
+
+	m.mu.Lock()
+	m.purge()
+	m.jobs[job.ID] = b
+	m.mu.Unlock()
+
+	go func() {
+		defer cancel()
+
+		b.mu.Lock()
+		b.startedAt = time.Now()
+		b.job.Status = models.JobStatusRunning
+		b.job.StartedAt = b.startedAt.Format(time.RFC3339)
+		b.mu.Unlock()
+
+		result, err := run(ctx, b)
+
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		b.job.FinishedAt = time.Now().Format(time.RFC3339)
+		b.job.Phase = ""
+		switch {
+		case ctx.Err() != nil && err != nil:
+			b.job.Status = models.JobStatusCancelled
+			b.job.Error = "cancelado por el usuario"
+		case err != nil:
+			b.job.Status = models.JobStatusFailed
+			b.job.Error = err.Error()
+		default:
+			b.job.Status = models.JobStatusCompleted
+			b.result = result
+		}
+
+		fmt.Printf("📋 Trabajo %s (%s) terminado: %s\n", b.job.ID, b.job.Type, b.job.Status)
+	}()
+
+	snapshot := b.snapshot()
+	return &snapshot, nil
+}
+
+// get retorna el trabajo por id
+func (m *jobManager) get(id string) (*backgroundJob, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	b, ok := m.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	return b, nil
+}
+
+// list retorna todos los trabajos, los más recientes primero
+func (m *jobManager) list() []models.Job {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	jobs := make([]models.Job, 0, len(m.jobs))
+	for _, b := range m.jobs {
+		jobs = append(jobs, b.snapshot())
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt > jobs[j].CreatedAt
+	})
+	return jobs
+}
+
+// purge descarta trabajos terminados viejos o en exceso (requiere mu tomado)
+func (m *jobManager) purge() {
+	var finished []*backgroundJob
+	for id, b := range m.jobs {
+		job := b.snapshot()
+		if !job.IsFinished() {
+			continue
+		}
+		if finishedAt, err := time.Parse(time.RFC3339, job.FinishedAt); err == nil && time.Since(finishedAt) > jobRetention {
+			delete(m.jobs, id)
+			continue
+		}
+		finished = append(finished, b)
+	}
+
+	if len(finished) < maxFinishedJobs {
+		return
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].snapshot().FinishedAt < finished[j].snapshot().FinishedAt
+	})
+	for _, b := range finished[:len(finished)-maxFinishedJobs+1] {
+		delete(m.jobs, b.snapshot().ID)
+	}
+}
+
+// 🚀 MÉTODOS DEL SERVICIO
+
+// StartImportJob inicia una importación en segundo plano
+func (s *ContactoService) StartImportJob(fileName string, data []byte, mode string) (*models.Job, []models.ErrorResponse, error) {
+	if _, ok := s.repo.(repositories.BulkContactoRepository); !ok {
+		return nil, nil, fmt.Errorf("importación no disponible")
+	}
+
+	// Validar modo y formato antes de aceptar el trabajo
+	if errores := validateImportRequest(fileName, mode); len(errores) > 0 {
+		return nil, errores, nil
+	}
+
+	job, err := s.jobs.start(models.Job{Type: JobTypeImport, FileName: fileName, Mode: mode},
+		func(ctx context.Context, b *backgroundJob) (interface{}, error) {
+			result, errores, err := s.importContactos(ctx, fileName, data, mode, b.updateProgress)
+			if err != nil {
+				return nil, err
+			}
+			if len(errores) > 0 {
+				return nil, fmt.Errorf("%s: %s", errores[0].Campo, errores[0].Mensaje)
+			}
+			return result, nil
+		})
+	return job, nil, err
+}
+
+// StartReloadJob inicia una recarga del Excel en segundo plano
+func (s *ContactoService) StartReloadJob() (*models.Job, error) {
+	return s.jobs.start(models.Job{Type: JobTypeReload},
+		func(ctx context.Context, b *backgroundJob) (interface{}, error) {
+			return s.reloadExcel(ctx, b.updateProgress)
+		})
+}
+
+// GetJob obtiene el estado de un trabajo
+func (s *ContactoService) GetJob(id string) (*models.Job, error) {
+	b, err := s.jobs.get(id)
+	if err != nil {
+		return nil, err
+	}
+	job := b.snapshot()
+	return &job, nil
+}
+
+// ListJobs lista los trabajos conocidos
+func (s *ContactoService) ListJobs() []models.Job {
+	return s.jobs.list()
+}
+
+// CancelJob solicita la cancelación de un trabajo en curso
+func (s *ContactoService) CancelJob(id string) (*models.Job, error) {
+	b, err := s.jobs.get(id)
+	if err != nil {
+		return nil, err
+	}
+	if job := b.snapshot(); job.IsFinished() {
+		return nil, ErrJobFinished
+	}
+
+	b.cancel()
+	job := b.snapshot()
+	return &job, nil
+}
+
+// GetJobResult obtiene el reporte final de un trabajo terminado
+func (s *ContactoService) GetJobResult(id string) (interface{}, error) {
+	b, err := s.jobs.get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	job := b.snapshot()
+	if !job.IsFinished() {
+		return nil, ErrJobNotFinished
+	}
+	if job.Status != models.JobStatusCompleted {
+		return nil, fmt.Errorf("%w: estado %s, %s", ErrJobFailed, job.Status, job.Error)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.result, nil
+}
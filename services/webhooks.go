@@ -0,0 +1,698 @@
+// services/webhooks.go
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	mathrand "math/rand/v2"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"contactos-api/models"
+)
+
+const (
+	// DefaultWebhookMaxAttempts intentos por entrega antes de marcarla como fallida
+	DefaultWebhookMaxAttempts = 8
+
+	webhookTimeout      = 10 * time.Second
+	webhookBaseBackoff  = 2 * time.Second
+	webhookMaxBackoff   = 10 * time.Minute
+	webhookPollInterval = time.Second
+	webhookWorkers      = 4
+	// maxFailedDeliveries entregas fallidas conservadas para inspección
+	maxFailedDeliveries = 500
+)
+
+// Encabezados enviados en cada entrega
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature" // sha256=<hex(HMAC(secret, timestamp + "." + body))>
+	WebhookTimestampHeader = "X-Webhook-Timestamp" // Segundos Unix usados en la firma
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+)
+
+var (
+	// ErrWebhooksDisabled el servicio se creó sin outbox de webhooks
+	ErrWebhooksDisabled = errors.New("webhooks no configurados")
+	// ErrWebhookNotFound la suscripción no existe
+	ErrWebhookNotFound = errors.New("suscripción no encontrada")
+	// ErrDeliveryNotFound la entrega no existe en el outbox
+	ErrDeliveryNotFound = errors.New("entrega no encontrada")
+	// ErrDeliveryNotFailed solo las entregas fallidas se pueden reintentar manualmente
+	ErrDeliveryNotFailed = errors.New("solo se pueden reintentar entregas fallidas")
+)
+
+// webhookEvents tipos de evento que se pueden suscribir
+var webhookEvents = map[string]bool{
+	models.EventCreated:           true,
+	models.EventUpdated:           true,
+	models.EventDeleted:           true,
+	models.EventReloaded:          true,
+	models.EventValidationChanged: true,
+}
+
+// webhookState contenido persistido: suscripciones y outbox
+type webhookState struct {
+	Subscriptions []models.WebhookSubscription `json:"subscriptions"`
+	Outbox        []models.WebhookDelivery     `json:"outbox"`
+}
+
+// webhookDispatcher guarda los eventos en un outbox en disco y los entrega con reintentos
+type webhookDispatcher struct {
+	path        string
+	maxAttempts int
+	client      *http.Client
+	lookup      func(claveCliente int) (*models.Contacto, error)
+	secrets     *secretBox
+
+	mu       sync.Mutex
+	state    webhookState
+	inFlight map[string]bool
+	wake     chan struct{}
+
+	// Eventos publicados que aún no pasan al outbox; journal los conserva en disco
+	queueMu sync.Mutex
+	queue   []models.ChangeEvent
+	queued  chan struct{}
+	journal *eventJournal
+}
+
+// newWebhookDispatcher carga el estado persistido; las entregas pendientes se reanudan y los
+// eventos que quedaron en la bitácora (path + ".journal") se vuelven a encolar.
+// Los secretos se guardan cifrados con secrets; los que estén en claro se cifran al cargar.
+func newWebhookDispatcher(path string, maxAttempts int, secrets *secretBox, lookup func(int) (*models.Contacto, error)) (*webhookDispatcher, error) {
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultWebhookMaxAttempts
+	}
+
+	d := &webhookDispatcher{
+		path:        path,
+		maxAttempts: maxAttempts,
+		client:      &http.Client{Timeout: webhookTimeout},
+		lookup:      lookup,
+		secrets:     secrets,
+		inFlight:    make(map[string]bool),
+		wake:        make(chan struct{}, 1),
+		queued:      make(chan struct{}, 1),
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("error leyendo webhooks: %w", err)
+	default:
+		if err := json.Unmarshal(data, &d.state); err != nil {
+			return nil, fmt.Errorf("archivo de webhooks inválido: %w", err)
+		}
+	}
+
+	if d.state.Subscriptions == nil {
+		d.state.Subscriptions = []models.WebhookSubscription{}
+	}
+	if d.state.Outbox == nil {
+		d.state.Outbox = []models.WebhookDelivery{}
+	}
+
+	enClaro := false
+	for i := range d.state.Subscriptions {
+		subscription := &d.state.Subscriptions[i]
+		if !isSealedSecret(subscription.Secret) {
+			enClaro = enClaro || subscription.Secret != ""
+			continue
+		}
+		secret, err := secrets.open(subscription.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo descifrar el secreto del webhook %s: %w", subscription.ID, err)
+		}
+		subscription.Secret = secret
+	}
+	if enClaro {
+		d.save()
+	}
+
+	journal, pending, err := openEventJournal(path + ".journal")
+	if err != nil {
+		return nil, err
+	}
+	d.journal = journal
+	if len(pending) > 0 {
+		d.queue = pending
+		d.queued <- struct{}{}
+	}
+
+	return d, nil
+}
+
+// run procesa el outbox hasta que termine el proceso
+func (d *webhookDispatcher) run() {
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-d.wake:
+		}
+		d.dispatchDue()
+	}
+}
+
+// dispatchDue envía las entregas pendientes cuyo siguiente intento ya venció
+func (d *webhookDispatcher) dispatchDue() {
+	now := time.Now()
+
+	d.mu.Lock()
+	var due []models.WebhookDelivery
+	for _, delivery := range d.state.Outbox {
+		if delivery.Status != models.DeliveryStatusPending || d.inFlight[delivery.ID] {
+			continue
+		}
+		if next, err := time.Parse(time.RFC3339Nano, delivery.NextAttemptAt); err == nil && next.After(now) {
+			continue
+		}
+		d.inFlight[delivery.ID] = true
+		due = append(due, delivery)
+	}
+	d.mu.Unlock()
+
+	sem := make(chan struct{}, webhookWorkers)
+	var wg sync.WaitGroup
+	for _, delivery := range due {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(delivery models.WebhookDelivery) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			d.deliver(delivery)
+		}(delivery)
+	}
+	wg.Wait()
+}
+
+// deliver hace un intento de entrega y registra el resultado
+func (d *webhookDispatcher) deliver(delivery models.WebhookDelivery) {
+	d.mu.Lock()
+	subscription, ok := d.findSubscription(delivery.SubscriptionID)
+	d.mu.Unlock()
+
+	statusCode := 0
+	var sendErr error
+	if !ok {
+		sendErr = errors.New("la suscripción fue eliminada")
+	} else {
+		statusCode, sendErr = d.send(subscription, delivery)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.inFlight, delivery.ID)
+
+	idx := d.findDelivery(delivery.ID)
+	if idx < 0 {
+		return
+	}
+	current := &d.state.Outbox[idx]
+	current.Attempts++
+	current.LastStatusCode = statusCode
+
+	switch {
+	case sendErr == nil:
+		// Entregada: sale del outbox
+		d.state.Outbox = append(d.state.Outbox[:idx], d.state.Outbox[idx+1:]...)
+	case !ok || current.Attempts >= d.maxAttempts:
+		current.Status = models.DeliveryStatusFailed
+		current.LastError = sendErr.Error()
+		current.NextAttemptAt = ""
+		fmt.Printf("❌ Webhook %s a %s falló tras %d intentos: %v\n", current.ID, current.URL, current.Attempts, sendErr)
+		d.trimFailed()
+	default:
+		current.LastError = sendErr.Error()
+		current.NextAttemptAt = time.Now().Add(webhookBackoff(current.Attempts)).Format(time.RFC3339Nano)
+	}
+
+	d.save()
+}
+
+// send firma y envía el payload; cualquier respuesta fuera de 2xx cuenta como fallo
+func (d *webhookDispatcher) send(subscription models.WebhookSubscription, delivery models.WebhookDelivery) (int, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "contactos-api-webhooks/1.0")
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID)
+	req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhookPayload(subscription.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("respuesta HTTP %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// SignWebhookPayload calcula la firma HMAC-SHA256 que el receptor debe verificar
+func SignWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookBackoff espera exponencial antes del siguiente intento, con ±20% de variación
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookMaxBackoff
+	if attempts < 20 {
+		if b := webhookBaseBackoff << (attempts - 1); b < webhookMaxBackoff {
+			backoff = b
+		}
+	}
+	jitter := time.Duration(float64(backoff) * (mathrand.Float64()*0.4 - 0.2))
+	return backoff + jitter
+}
+
+// enqueue encola el evento y lo agrega a la bitácora antes de retornar, para que sobreviva a un
+// reinicio; se llama desde eventBroker.publish, a veces con loadMu tomado, por lo que no consulta
+// el repositorio ni toca el outbox
+func (d *webhookDispatcher) enqueue(event models.ChangeEvent) {
+	d.queueMu.Lock()
+	if err := d.journal.append(event); err != nil {
+		fmt.Printf("⚠️ Error registrando evento %d en la bitácora de webhooks: %v\n", event.ID, err)
+	}
+	d.queue = append(d.queue, event)
+	d.queueMu.Unlock()
+
+	select {
+	case d.queued <- struct{}{}:
+	default:
+	}
+}
+
+// runQueue pasa los eventos encolados al outbox, en lotes, hasta que termine el proceso; la
+// bitácora se recorta solo después de que el outbox quedó guardado
+func (d *webhookDispatcher) runQueue() {
+	for range d.queued {
+		d.queueMu.Lock()
+		events := d.queue
+		d.queue = nil
+		d.queueMu.Unlock()
+
+		if len(events) == 0 {
+			continue
+		}
+		if err := d.record(events); err != nil {
+			continue // Siguen en la bitácora; el siguiente guardado del outbox los incluye
+		}
+
+		d.queueMu.Lock()
+		if err := d.journal.reset(d.queue); err != nil {
+			fmt.Printf("⚠️ Error recortando la bitácora de webhooks: %v\n", err)
+		}
+		d.queueMu.Unlock()
+	}
+}
+
+// record agrega al outbox una entrega por cada evento y suscripción interesada, con un solo guardado.
+// Los contactos del payload se leen aquí, así que reflejan el estado al momento de encolar la entrega.
+func (d *webhookDispatcher) record(events []models.ChangeEvent) error {
+	d.mu.Lock()
+	subscriptions := append([]models.WebhookSubscription(nil), d.state.Subscriptions...)
+	d.mu.Unlock()
+
+	var deliveries []models.WebhookDelivery
+	for _, event := range events {
+		var destinos []models.WebhookSubscription
+		for _, subscription := range subscriptions {
+			if subscription.Active && subscribedTo(subscription, event.Type) {
+				destinos = append(destinos, subscription)
+			}
+		}
+		if len(destinos) == 0 {
+			continue
+		}
+		deliveries = append(deliveries, d.buildDeliveries(event, destinos)...)
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	d.mu.Lock()
+	d.state.Outbox = append(d.state.Outbox, deliveries...)
+	err := d.save()
+	d.mu.Unlock()
+	d.signal()
+	return err
+}
+
+// buildDeliveries arma el payload del evento para cada suscripción destino
+func (d *webhookDispatcher) buildDeliveries(event models.ChangeEvent, destinos []models.WebhookSubscription) []models.WebhookDelivery {
+	payload := models.WebhookPayload{
+		EventID:   event.ID,
+		Event:     event.Type,
+		Source:    event.Source,
+		Timestamp: event.Timestamp,
+		Claves:    event.Claves,
+		Count:     event.Count,
+		Truncated: event.Truncated,
+	}
+
+	// Incluir el estado actual de los contactos creados o actualizados
+	if event.Type == models.EventCreated || event.Type == models.EventUpdated {
+		for _, clave := range event.Claves {
+			if contacto, err := d.lookup(clave); err == nil {
+				payload.Contactos = append(payload.Contactos, *contacto)
+			}
+		}
+	}
+
+	now := time.Now()
+	deliveries := make([]models.WebhookDelivery, 0, len(destinos))
+	for _, subscription := range destinos {
+		id, err := randomHex(12)
+		if err != nil {
+			fmt.Printf("⚠️ No se pudo encolar webhook: %v\n", err)
+			continue
+		}
+		payload.DeliveryID = id
+
+		body, err := json.Marshal(payload)
+		if err != nil {
+			fmt.Printf("⚠️ No se pudo serializar webhook: %v\n", err)
+			continue
+		}
+
+		deliveries = append(deliveries, models.WebhookDelivery{
+			ID:             id,
+			SubscriptionID: subscription.ID,
+			URL:            subscription.URL,
+			Event:          event.Type,
+			Payload:        body,
+			Status:         models.DeliveryStatusPending,
+			NextAttemptAt:  now.Format(time.RFC3339Nano),
+			CreatedAt:      now.Format(time.RFC3339),
+		})
+	}
+	return deliveries
+}
+
+func (d *webhookDispatcher) signal() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// save persiste el estado con escritura atómica y los secretos cifrados (requiere mu tomado)
+func (d *webhookDispatcher) save() error {
+	stored := webhookState{
+		Subscriptions: make([]models.WebhookSubscription, len(d.state.Subscriptions)),
+		Outbox:        d.state.Outbox,
+	}
+	for i, subscription := range d.state.Subscriptions {
+		sealed, err := d.secrets.seal(subscription.Secret)
+		if err != nil {
+			fmt.Printf("⚠️ Error cifrando secreto de webhook: %v\n", err)
+			return err
+		}
+		subscription.Secret = sealed
+		stored.Subscriptions[i] = subscription
+	}
+
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		fmt.Printf("⚠️ Error serializando webhooks: %v\n", err)
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(d.path), ".webhooks-*.tmp")
+	if err != nil {
+		fmt.Printf("⚠️ Error guardando webhooks: %v\n", err)
+		return err
+	}
+	// Sync antes del rename: tras una caída el archivo es el anterior o el nuevo completo
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Sync()
+	}
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		fmt.Printf("⚠️ Error guardando webhooks: %v\n", err)
+		return err
+	}
+	tmp.Close()
+
+	if err := os.Rename(tmp.Name(), d.path); err != nil {
+		os.Remove(tmp.Name())
+		fmt.Printf("⚠️ Error guardando webhooks: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+// trimFailed descarta las entregas fallidas más viejas por encima del límite (requiere mu tomado)
+func (d *webhookDispatcher) trimFailed() {
+	failed := 0
+	for _, delivery := range d.state.Outbox {
+		if delivery.Status == models.DeliveryStatusFailed {
+			failed++
+		}
+	}
+
+	outbox := d.state.Outbox[:0]
+	for _, delivery := range d.state.Outbox {
+		if failed > maxFailedDeliveries && delivery.Status == models.DeliveryStatusFailed {
+			failed--
+			continue
+		}
+		outbox = append(outbox, delivery)
+	}
+	d.state.Outbox = outbox
+}
+
+// findSubscription busca una suscripción por id (requiere mu tomado)
+func (d *webhookDispatcher) findSubscription(id string) (models.WebhookSubscription, bool) {
+	for _, subscription := range d.state.Subscriptions {
+		if subscription.ID == id {
+			return subscription, true
+		}
+	}
+	return models.WebhookSubscription{}, false
+}
+
+// findDelivery retorna la posición de una entrega en el outbox o -1 (requiere mu tomado)
+func (d *webhookDispatcher) findDelivery(id string) int {
+	for i, delivery := range d.state.Outbox {
+		if delivery.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// subscribedTo indica si la suscripción recibe el tipo de evento
+func subscribedTo(subscription models.WebhookSubscription, eventType string) bool {
+	if len(subscription.Events) == 0 {
+		return true
+	}
+	for _, e := range subscription.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// 🚀 MÉTODOS DEL SERVICIO
+
+// ConfigureWebhooks activa los webhooks con el estado y outbox persistidos en path. Los secretos
+// se cifran con secretKey; sin ella se usa una llave aleatoria guardada en path + ".key".
+func (s *ContactoService) ConfigureWebhooks(path string, maxAttempts int, secretKey string) error {
+	secrets, err := newSecretBox(secretKey, path+".key")
+	if err != nil {
+		return err
+	}
+	dispatcher, err := newWebhookDispatcher(path, maxAttempts, secrets, s.repo.GetByID)
+	if err != nil {
+		return err
+	}
+
+	s.webhooks = dispatcher
+	s.events.addListener(dispatcher.enqueue)
+	go dispatcher.runQueue()
+	go dispatcher.run()
+
+	return nil
+}
+
+// ListWebhooks lista las suscripciones sin exponer sus secretos
+func (s *ContactoService) ListWebhooks() ([]models.WebhookSubscription, error) {
+	if s.webhooks == nil {
+		return nil, ErrWebhooksDisabled
+	}
+
+	s.webhooks.mu.Lock()
+	defer s.webhooks.mu.Unlock()
+
+	subscriptions := make([]models.WebhookSubscription, len(s.webhooks.state.Subscriptions))
+	for i, subscription := range s.webhooks.state.Subscriptions {
+		subscription.Secret = ""
+		subscriptions[i] = subscription
+	}
+	return subscriptions, nil
+}
+
+// CreateWebhook registra una suscripción; el secreto solo se retorna en esta respuesta
+func (s *ContactoService) CreateWebhook(request *models.WebhookRequest) (*models.WebhookSubscription, []models.ErrorResponse, error) {
+	if s.webhooks == nil {
+		return nil, nil, ErrWebhooksDisabled
+	}
+
+	var errores []models.ErrorResponse
+	if parsed, err := url.Parse(request.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		errores = append(errores, models.ErrorResponse{Campo: "url", Mensaje: "La URL debe ser http(s) absoluta"})
+	}
+	for _, e := range request.Events {
+		if !webhookEvents[e] {
+			errores = append(errores, models.ErrorResponse{
+				Campo:   "events",
+				Mensaje: fmt.Sprintf("Evento '%s' inválido. Use created, updated, deleted, reloaded o validation-changed", e),
+			})
+		}
+	}
+	if len(errores) > 0 {
+		return nil, errores, nil
+	}
+
+	id, err := randomHex(8)
+	if err != nil {
+		return nil, nil, err
+	}
+	secret := request.Secret
+	if secret == "" {
+		if secret, err = randomHex(32); err != nil {
+			return nil, nil, err
+		}
+	}
+	events := request.Events
+	if events == nil {
+		events = []string{}
+	}
+
+	subscription := models.WebhookSubscription{
+		ID:        id,
+		URL:       request.URL,
+		Events:    events,
+		Secret:    secret,
+		Active:    true,
+		CreatedAt: time.Now().Format(time.RFC3339),
+	}
+
+	s.webhooks.mu.Lock()
+	s.webhooks.state.Subscriptions = append(s.webhooks.state.Subscriptions, subscription)
+	s.webhooks.save()
+	s.webhooks.mu.Unlock()
+
+	return &subscription, nil, nil
+}
+
+// DeleteWebhook elimina una suscripción; sus entregas pendientes fallarán
+func (s *ContactoService) DeleteWebhook(id string) error {
+	if s.webhooks == nil {
+		return ErrWebhooksDisabled
+	}
+
+	s.webhooks.mu.Lock()
+	defer s.webhooks.mu.Unlock()
+
+	for i, subscription := range s.webhooks.state.Subscriptions {
+		if subscription.ID == id {
+			s.webhooks.state.Subscriptions = append(s.webhooks.state.Subscriptions[:i], s.webhooks.state.Subscriptions[i+1:]...)
+			s.webhooks.save()
+			return nil
+		}
+	}
+	return ErrWebhookNotFound
+}
+
+// ListWebhookDeliveries lista las entregas del outbox con el estado indicado (vacío = todas)
+func (s *ContactoService) ListWebhookDeliveries(status string) ([]models.WebhookDelivery, error) {
+	if s.webhooks == nil {
+		return nil, ErrWebhooksDisabled
+	}
+
+	s.webhooks.mu.Lock()
+	defer s.webhooks.mu.Unlock()
+
+	deliveries := []models.WebhookDelivery{}
+	for _, delivery := range s.webhooks.state.Outbox {
+		if status == "" || delivery.Status == status {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return deliveries, nil
+}
+
+// RetryWebhookDelivery vuelve a encolar una entrega fallida con sus intentos reiniciados
+func (s *ContactoService) RetryWebhookDelivery(id string) (*models.WebhookDelivery, error) {
+	if s.webhooks == nil {
+		return nil, ErrWebhooksDisabled
+	}
+
+	s.webhooks.mu.Lock()
+	defer s.webhooks.mu.Unlock()
+
+	idx := s.webhooks.findDelivery(id)
+	if idx < 0 {
+		return nil, ErrDeliveryNotFound
+	}
+	delivery := &s.webhooks.state.Outbox[idx]
+	if delivery.Status != models.DeliveryStatusFailed {
+		return nil, ErrDeliveryNotFailed
+	}
+	if _, ok := s.webhooks.findSubscription(delivery.SubscriptionID); !ok {
+		return nil, ErrWebhookNotFound
+	}
+
+	delivery.Status = models.DeliveryStatusPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now().Format(time.RFC3339Nano)
+	retried := *delivery
+
+	s.webhooks.save()
+	s.webhooks.signal()
+
+	return &retried, nil
+}
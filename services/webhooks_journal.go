@@ -0,0 +1,86 @@
+// services/webhooks_journal.go
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"contactos-api/models"
+)
+
+// eventJournal bitácora en disco de los eventos publicados que aún no pasan al outbox: enqueue
+// agrega cada evento antes de retornar y, una vez guardados en el outbox, la bitácora se reescribe
+// con los que siguen en cola. Al iniciar se vuelven a encolar los que quedaron (una caída entre el
+// guardado del outbox y la reescritura puede repetir una entrega, pero no perderla).
+type eventJournal struct {
+	file *os.File
+}
+
+// openEventJournal abre la bitácora y retorna los eventos que quedaron pendientes
+func openEventJournal(path string) (*eventJournal, []models.ChangeEvent, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, nil, fmt.Errorf("error leyendo bitácora de eventos: %w", err)
+	}
+
+	var pending []models.ChangeEvent
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var event models.ChangeEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			continue // Línea cortada por una caída a mitad de escritura
+		}
+		pending = append(pending, event)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error abriendo bitácora de eventos: %w", err)
+	}
+	journal := &eventJournal{file: file}
+
+	// Reescribir sin la línea cortada para que lo que se agregue después no quede pegado a ella
+	if err := journal.reset(pending); err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("error reescribiendo bitácora de eventos: %w", err)
+	}
+	return journal, pending, nil
+}
+
+// append agrega los eventos y espera a que lleguen al disco
+func (j *eventJournal) append(events ...models.ChangeEvent) error {
+	var buf bytes.Buffer
+	for _, event := range events {
+		line, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	if _, err := j.file.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	return j.file.Sync()
+}
+
+// reset deja en la bitácora solo los eventos indicados
+func (j *eventJournal) reset(pending []models.ChangeEvent) error {
+	if err := j.file.Truncate(0); err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		return j.file.Sync()
+	}
+	return j.append(pending...)
+}
+
+// close cierra el archivo de la bitácora
+func (j *eventJournal) close() error {
+	return j.file.Close()
+}
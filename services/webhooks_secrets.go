@@ -0,0 +1,114 @@
+// services/webhooks_secrets.go
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// sealedSecretPrefix marca los secretos cifrados en el archivo de webhooks
+const sealedSecretPrefix = "enc:v1:"
+
+// secretBox cifra los secretos de las suscripciones con AES-256-GCM; el secreto en claro
+// solo vive en memoria porque se necesita para firmar cada entrega
+type secretBox struct {
+	aead cipher.AEAD
+}
+
+// newSecretBox deriva la llave de key; sin key la lee de keyFile, creándola si no existe
+func newSecretBox(key, keyFile string) (*secretBox, error) {
+	if key == "" {
+		loaded, err := loadOrCreateKey(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		key = loaded
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &secretBox{aead: aead}, nil
+}
+
+// loadOrCreateKey lee la llave de keyFile o genera una aleatoria legible solo por el dueño
+func loadOrCreateKey(keyFile string) (string, error) {
+	data, err := os.ReadFile(keyFile)
+	if err == nil {
+		if key := strings.TrimSpace(string(data)); key != "" {
+			return key, nil
+		}
+		return "", fmt.Errorf("llave de webhooks vacía en %s", keyFile)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("error leyendo llave de webhooks: %w", err)
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	key := hex.EncodeToString(buf)
+
+	file, err := os.OpenFile(keyFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("error creando llave de webhooks: %w", err)
+	}
+	if _, err = file.WriteString(key + "\n"); err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(keyFile)
+		return "", fmt.Errorf("error guardando llave de webhooks: %w", err)
+	}
+	return key, nil
+}
+
+// isSealedSecret indica si el valor guardado está cifrado
+func isSealedSecret(value string) bool {
+	return strings.HasPrefix(value, sealedSecretPrefix)
+}
+
+// seal cifra el secreto con un nonce aleatorio ("" se conserva vacío)
+func (b *secretBox) seal(secret string) (string, error) {
+	if secret == "" {
+		return "", nil
+	}
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := b.aead.Seal(nonce, nonce, []byte(secret), nil)
+	return sealedSecretPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// open descifra un secreto guardado con seal
+func (b *secretBox) open(value string) (string, error) {
+	data, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(value, sealedSecretPrefix))
+	if err != nil || len(data) < b.aead.NonceSize() {
+		return "", errors.New("secreto cifrado inválido")
+	}
+	nonce, ciphertext := data[:b.aead.NonceSize()], data[b.aead.NonceSize():]
+	secret, err := b.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", errors.New("llave incorrecta o secreto alterado")
+	}
+	return string(secret), nil
+}
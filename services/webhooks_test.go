@@ -0,0 +1,388 @@
+// services/webhooks_test.go
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"contactos-api/models"
+)
+
+const testWebhookSecret = "secreto-de-prueba"
+
+// newTestDispatcher dispatcher sin goroutines con una suscripción a url; el estado vive en dir
+func newTestDispatcher(t *testing.T, dir, url string, maxAttempts int) *webhookDispatcher {
+	t.Helper()
+
+	path := filepath.Join(dir, "webhooks.json")
+	secrets, err := newSecretBox("", path+".key")
+	if err != nil {
+		t.Fatalf("newSecretBox: %v", err)
+	}
+	lookup := func(int) (*models.Contacto, error) { return nil, errors.New("sin contactos") }
+	d, err := newWebhookDispatcher(path, maxAttempts, secrets, lookup)
+	if err != nil {
+		t.Fatalf("newWebhookDispatcher: %v", err)
+	}
+
+	if url != "" {
+		d.mu.Lock()
+		d.state.Subscriptions = append(d.state.Subscriptions, models.WebhookSubscription{
+			ID:     "sub1",
+			URL:    url,
+			Events: []string{},
+			Secret: testWebhookSecret,
+			Active: true,
+		})
+		d.save()
+		d.mu.Unlock()
+	}
+	return d
+}
+
+func testEvent(id uint64) models.ChangeEvent {
+	return models.ChangeEvent{ID: id, Type: models.EventDeleted, Source: "test", Claves: []int{7}, Count: 1}
+}
+
+func outboxOf(d *webhookDispatcher) []models.WebhookDelivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]models.WebhookDelivery(nil), d.state.Outbox...)
+}
+
+// makeDue adelanta el siguiente intento de todas las entregas para no esperar el backoff
+func makeDue(d *webhookDispatcher) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i := range d.state.Outbox {
+		d.state.Outbox[i].NextAttemptAt = time.Now().Add(-time.Second).Format(time.RFC3339Nano)
+	}
+}
+
+func TestWebhookSignature(t *testing.T) {
+	var mu sync.Mutex
+	var received []*http.Request
+	var bodies [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received = append(received, r)
+		bodies = append(bodies, body)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	d := newTestDispatcher(t, t.TempDir(), server.URL, 3)
+	d.record([]models.ChangeEvent{testEvent(1)})
+	d.dispatchDue()
+
+	if len(received) != 1 {
+		t.Fatalf("entregas recibidas = %d, se esperaba 1", len(received))
+	}
+	req, body := received[0], bodies[0]
+
+	// El receptor verifica con HMAC-SHA256(secret, timestamp + "." + body)
+	mac := hmac.New(sha256.New, []byte(testWebhookSecret))
+	mac.Write([]byte(req.Header.Get(WebhookTimestampHeader) + "."))
+	mac.Write(body)
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if got := req.Header.Get(WebhookSignatureHeader); !hmac.Equal([]byte(got), []byte(want)) {
+		t.Errorf("firma = %q, se esperaba %q", got, want)
+	}
+	if req.Header.Get(WebhookEventHeader) != models.EventDeleted {
+		t.Errorf("evento = %q", req.Header.Get(WebhookEventHeader))
+	}
+
+	var payload models.WebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("payload inválido: %v", err)
+	}
+	if payload.DeliveryID != req.Header.Get(WebhookDeliveryHeader) || payload.EventID != 1 {
+		t.Errorf("payload = %+v", payload)
+	}
+	if outbox := outboxOf(d); len(outbox) != 0 {
+		t.Errorf("la entrega exitosa sigue en el outbox: %+v", outbox)
+	}
+}
+
+func TestWebhookRetryBackoff(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		n := calls
+		mu.Unlock()
+		if n <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	d := newTestDispatcher(t, t.TempDir(), server.URL, 3)
+	d.record([]models.ChangeEvent{testEvent(1)})
+
+	before := time.Now()
+	d.dispatchDue()
+	outbox := outboxOf(d)
+	if len(outbox) != 1 {
+		t.Fatalf("outbox = %d entregas, se esperaba 1", len(outbox))
+	}
+	delivery := outbox[0]
+	if delivery.Attempts != 1 || delivery.Status != models.DeliveryStatusPending || delivery.LastStatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("tras el primer fallo: %+v", delivery)
+	}
+	next, err := time.Parse(time.RFC3339Nano, delivery.NextAttemptAt)
+	if err != nil {
+		t.Fatalf("nextAttemptAt inválido: %v", err)
+	}
+	if wait := next.Sub(before); wait < webhookBaseBackoff*8/10 || wait > webhookBaseBackoff*12/10+time.Second {
+		t.Errorf("espera antes del reintento = %v, se esperaba ~%v", wait, webhookBaseBackoff)
+	}
+
+	// Antes de vencer el backoff no se reintenta
+	d.dispatchDue()
+	if outboxOf(d)[0].Attempts != 1 {
+		t.Errorf("se reintentó antes de vencer el backoff")
+	}
+
+	makeDue(d)
+	d.dispatchDue()
+	if delivery := outboxOf(d)[0]; delivery.Attempts != 2 || delivery.Status != models.DeliveryStatusPending {
+		t.Fatalf("tras el segundo fallo: %+v", delivery)
+	}
+
+	makeDue(d)
+	d.dispatchDue()
+	if outbox := outboxOf(d); len(outbox) != 0 {
+		t.Errorf("la entrega exitosa sigue en el outbox: %+v", outbox)
+	}
+}
+
+func TestWebhookFailsAfterMaxAttempts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	d := newTestDispatcher(t, t.TempDir(), server.URL, 2)
+	d.record([]models.ChangeEvent{testEvent(1)})
+	d.dispatchDue()
+	makeDue(d)
+	d.dispatchDue()
+
+	outbox := outboxOf(d)
+	if len(outbox) != 1 || outbox[0].Status != models.DeliveryStatusFailed || outbox[0].Attempts != 2 {
+		t.Fatalf("outbox = %+v, se esperaba una entrega fallida con 2 intentos", outbox)
+	}
+	if outbox[0].NextAttemptAt != "" || outbox[0].LastError == "" {
+		t.Errorf("entrega fallida sin error o con reintento programado: %+v", outbox[0])
+	}
+}
+
+func TestWebhookBackoffGrows(t *testing.T) {
+	for attempts := 1; attempts <= 30; attempts++ {
+		base := webhookMaxBackoff
+		if attempts < 20 && webhookBaseBackoff<<(attempts-1) < webhookMaxBackoff {
+			base = webhookBaseBackoff << (attempts - 1)
+		}
+		got := webhookBackoff(attempts)
+		if got < base*8/10 || got > base*12/10 {
+			t.Errorf("webhookBackoff(%d) = %v, fuera de %v ±20%%", attempts, got, base)
+		}
+	}
+}
+
+func TestWebhookOutboxReplayAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+
+	// Primer proceso: el receptor no responde y la entrega queda pendiente en disco
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := down.URL
+	down.Close()
+
+	first := newTestDispatcher(t, dir, url, 5)
+	first.record([]models.ChangeEvent{testEvent(1), testEvent(2)})
+	first.dispatchDue()
+	pending := outboxOf(first)
+	if len(pending) != 2 || pending[0].Attempts != 1 {
+		t.Fatalf("outbox antes de reiniciar = %+v", pending)
+	}
+
+	// Segundo proceso: carga el mismo archivo y entrega lo pendiente en el receptor ya levantado
+	var mu sync.Mutex
+	delivered := map[string]string{}
+	listener := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mac := hmac.New(sha256.New, []byte(testWebhookSecret))
+		mac.Write([]byte(r.Header.Get(WebhookTimestampHeader) + "."))
+		mac.Write(body)
+		if r.Header.Get(WebhookSignatureHeader) != "sha256="+hex.EncodeToString(mac.Sum(nil)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		delivered[r.Header.Get(WebhookDeliveryHeader)] = string(body)
+		mu.Unlock()
+	}))
+	defer listener.Close()
+	listener.Start()
+
+	second := newTestDispatcher(t, dir, "", 5)
+	second.mu.Lock()
+	second.state.Subscriptions[0].URL = listener.URL
+	second.mu.Unlock()
+	if replayed := outboxOf(second); len(replayed) != 2 || replayed[0].ID != pending[0].ID || replayed[0].Attempts != 1 {
+		t.Fatalf("outbox recargado = %+v", replayed)
+	}
+
+	makeDue(second)
+	second.dispatchDue()
+	for _, delivery := range pending {
+		if _, ok := delivered[delivery.ID]; !ok {
+			t.Errorf("la entrega %s no se reanudó tras reiniciar", delivery.ID)
+		}
+	}
+	if outbox := outboxOf(second); len(outbox) != 0 {
+		t.Errorf("outbox tras reanudar = %+v", outbox)
+	}
+}
+
+func TestWebhookSecretsEncryptedAtRest(t *testing.T) {
+	dir := t.TempDir()
+	newTestDispatcher(t, dir, "http://example.invalid/hook", 3)
+
+	path := filepath.Join(dir, "webhooks.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), testWebhookSecret) {
+		t.Fatalf("el secreto se guardó en claro: %s", data)
+	}
+
+	// Se recupera con la misma llave
+	reopened := newTestDispatcher(t, dir, "", 3)
+	if secret := reopened.state.Subscriptions[0].Secret; secret != testWebhookSecret {
+		t.Errorf("secreto descifrado = %q", secret)
+	}
+
+	// Con otra llave la carga falla en lugar de firmar con basura
+	other, err := newSecretBox("otra-llave", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newWebhookDispatcher(path, 3, other, nil); err == nil {
+		t.Error("se esperaba error al descifrar con otra llave")
+	}
+}
+
+func TestWebhookLegacyPlaintextSecretIsSealed(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "webhooks.json")
+	legacy := `{"subscriptions":[{"id":"sub1","url":"http://example.invalid","events":[],"secret":"` + testWebhookSecret + `","active":true,"createdAt":""}],"outbox":[]}`
+	if err := os.WriteFile(path, []byte(legacy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	d := newTestDispatcher(t, dir, "", 3)
+	if d.state.Subscriptions[0].Secret != testWebhookSecret {
+		t.Errorf("secreto en memoria = %q", d.state.Subscriptions[0].Secret)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), testWebhookSecret) {
+		t.Errorf("el secreto en claro no se cifró al cargar: %s", data)
+	}
+}
+
+func TestWebhookEnqueueDoesNotBlock(t *testing.T) {
+	d := newTestDispatcher(t, t.TempDir(), "http://example.invalid/hook", 3)
+
+	// Aunque el outbox esté ocupado (p. ej. guardando en disco), publicar no espera
+	d.mu.Lock()
+	done := make(chan struct{})
+	go func() {
+		d.enqueue(testEvent(1))
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		d.mu.Unlock()
+		t.Fatal("enqueue se bloqueó con el outbox ocupado")
+	}
+	d.mu.Unlock()
+
+	go d.runQueue()
+	deadline := time.Now().Add(2 * time.Second)
+	for len(outboxOf(d)) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("el evento encolado no llegó al outbox")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWebhookQueuedEventsSurviveCrash(t *testing.T) {
+	dir := t.TempDir()
+
+	// Primer proceso: runQueue nunca corre, muere con los eventos solo encolados
+	first := newTestDispatcher(t, dir, "http://example.invalid/hook", 3)
+	first.enqueue(testEvent(1))
+	first.enqueue(testEvent(2))
+	if outbox := outboxOf(first); len(outbox) != 0 {
+		t.Fatalf("outbox antes de la caída = %+v", outbox)
+	}
+	first.journal.close()
+
+	// Segundo proceso: los eventos de la bitácora llegan al outbox
+	second := newTestDispatcher(t, dir, "", 3)
+	go second.runQueue()
+	deadline := time.Now().Add(2 * time.Second)
+	for len(outboxOf(second)) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("los eventos encolados se perdieron al reiniciar: outbox = %+v", outboxOf(second))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	ids := map[uint64]bool{}
+	for _, delivery := range outboxOf(second) {
+		var payload models.WebhookPayload
+		if err := json.Unmarshal(delivery.Payload, &payload); err != nil {
+			t.Fatal(err)
+		}
+		ids[payload.EventID] = true
+	}
+	if !ids[1] || !ids[2] {
+		t.Errorf("eventos recuperados = %v, se esperaban 1 y 2", ids)
+	}
+
+	// Ya guardados en el outbox, salen de la bitácora
+	journal := filepath.Join(dir, "webhooks.json.journal")
+	for {
+		info, err := os.Stat(journal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("la bitácora conserva %d bytes tras guardar el outbox", info.Size())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,54 @@
+// utils/normalize.go
+package utils
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// foldPool transformadores reutilizables: un transform.Chain no es seguro para uso concurrente
+var foldPool = sync.Pool{
+	New: func() interface{} {
+		return transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), cases.Fold())
+	},
+}
+
+// NormalizeText forma canónica para buscar: descompone (NFD), quita diacríticos,
+// ignora mayúsculas y colapsa espacios. "  NÚÑEZ   Pérez " -> "nunez perez"
+func NormalizeText(text string) string {
+	if isASCII(text) {
+		return collapseSpaces(strings.ToLower(text))
+	}
+
+	t := foldPool.Get().(transform.Transformer)
+	folded, _, err := transform.String(t, text)
+	foldPool.Put(t)
+	if err != nil {
+		folded = strings.ToLower(text)
+	}
+	return collapseSpaces(folded)
+}
+
+// collapseSpaces recorta y deja un solo espacio entre palabras
+func collapseSpaces(text string) string {
+	if !strings.Contains(text, "  ") && strings.TrimSpace(text) == text && !strings.ContainsAny(text, "\t\n\r\v\f") {
+		return text
+	}
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func isASCII(text string) bool {
+	for i := 0; i < len(text); i++ {
+		if text[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
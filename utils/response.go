@@ -46,6 +46,22 @@ func CreatedResponse(w http.ResponseWriter, data interface{}) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// AcceptedResponse envía una respuesta de trabajo aceptado para procesarse en segundo plano
+func AcceptedResponse(w http.ResponseWriter, data interface{}, location string) {
+	response := APIResponse{
+		Success: true,
+		Data:    data,
+		Message: "Solicitud aceptada; consulte el estado del trabajo",
+	}
+	
+	w.Header().Set("Content-Type", "application/json")
+	if location != "" {
+		w.Header().Set("Location", location)
+	}
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(response)
+}
+
 // BadRequestResponse envía una respuesta de solicitud inválida
 func BadRequestResponse(w http.ResponseWriter, message string) {
 	response := APIResponse{
@@ -70,6 +86,18 @@ func NotFoundResponse(w http.ResponseWriter, message string) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// ConflictResponse envía una respuesta de conflicto con el estado actual
+func ConflictResponse(w http.ResponseWriter, message string) {
+	response := APIResponse{
+		Success: false,
+		Error:   message,
+	}
+	
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(response)
+}
+
 // InternalServerErrorResponse envía una respuesta de error interno del servidor
 func InternalServerErrorResponse(w http.ResponseWriter, message string) {
 	response := APIResponse{
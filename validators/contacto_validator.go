@@ -2,13 +2,45 @@
 package validators
 
 import (
+	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"contactos-api/models"
 )
 
+// Valores por defecto de las reglas de validación
+const (
+	DefaultTelefonoDigitos = 10
+	DefaultClaveMinima     = 1
+	// NombreCaracteres clase de caracteres permitidos en el nombre (sintaxis de regexp)
+	NombreCaracteres = `a-zA-ZáéíóúÁÉÍÓÚñÑ\s`
+)
+
+// DefaultDominiosCorreo proveedores de correo aceptados por defecto
+var DefaultDominiosCorreo = []string{
+	"gmail.com", "yahoo.com", "hotmail.com", "outlook.com", "live.com", "icloud.com", "protonmail.com",
+}
+
+// ValidatorConfig reglas activas del validador; también se usan para generar la plantilla de importación
+type ValidatorConfig struct {
+	TelefonoDigitos int      `json:"telefonoDigitos"`
+	DominiosCorreo  []string `json:"dominiosCorreo"`
+	ClaveMinima     int      `json:"claveMinima"`
+}
+
+// DefaultValidatorConfig retorna las reglas por defecto
+func DefaultValidatorConfig() ValidatorConfig {
+	return ValidatorConfig{
+		TelefonoDigitos: DefaultTelefonoDigitos,
+		DominiosCorreo:  append([]string(nil), DefaultDominiosCorreo...),
+		ClaveMinima:     DefaultClaveMinima,
+	}
+}
+
 // ContactoValidator maneja las validaciones de contactos
 type ContactoValidator struct {
+	config        ValidatorConfig
 	telefonoRegex *regexp.Regexp
 	correoRegex   *regexp.Regexp
 	nombreRegex   *regexp.Regexp
@@ -16,11 +48,80 @@ type ContactoValidator struct {
 
 // NewContactoValidator crea una nueva instancia del validador
 func NewContactoValidator() *ContactoValidator {
+	validator, _ := NewContactoValidatorWithConfig(DefaultValidatorConfig())
+	return validator
+}
+
+// NewContactoValidatorWithConfig crea un validador con reglas personalizadas
+func NewContactoValidatorWithConfig(config ValidatorConfig) (*ContactoValidator, error) {
+	if config.TelefonoDigitos <= 0 {
+		return nil, fmt.Errorf("telefonoDigitos debe ser mayor a 0")
+	}
+	if config.ClaveMinima <= 0 {
+		return nil, fmt.Errorf("claveMinima debe ser mayor a 0")
+	}
+
+	dominios := make([]string, 0, len(config.DominiosCorreo))
+	for _, dominio := range config.DominiosCorreo {
+		dominio = strings.ToLower(strings.TrimSpace(dominio))
+		if dominio == "" {
+			continue
+		}
+		if strings.ContainsAny(dominio, "@,\" \t") {
+			return nil, fmt.Errorf("dominio de correo inválido: %q", dominio)
+		}
+		dominios = append(dominios, dominio)
+	}
+	if len(dominios) == 0 {
+		return nil, fmt.Errorf("se requiere al menos un dominio de correo")
+	}
+	config.DominiosCorreo = dominios
+
+	quoted := make([]string, len(dominios))
+	for i, dominio := range dominios {
+		quoted[i] = regexp.QuoteMeta(dominio)
+	}
+
 	return &ContactoValidator{
-		telefonoRegex: regexp.MustCompile(`^\d{10}$`),
-		correoRegex:   regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@(gmail\.com|yahoo\.com|hotmail\.com|outlook\.com|live\.com|icloud\.com|protonmail\.com)$`),
-		nombreRegex:   regexp.MustCompile(`^[a-zA-ZáéíóúÁÉÍÓÚñÑ\s]+$`),
+		config:        config,
+		telefonoRegex: regexp.MustCompile(fmt.Sprintf(`^\d{%d}$`, config.TelefonoDigitos)),
+		correoRegex:   regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@(` + strings.Join(quoted, "|") + `)$`),
+		nombreRegex:   regexp.MustCompile(`^[` + NombreCaracteres + `]+$`),
+	}, nil
+}
+
+// Config retorna una copia de las reglas activas
+func (v *ContactoValidator) Config() ValidatorConfig {
+	config := v.config
+	config.DominiosCorreo = append([]string(nil), v.config.DominiosCorreo...)
+	return config
+}
+
+// TelefonoMensaje mensaje de error del teléfono según la configuración
+func (v *ContactoValidator) TelefonoMensaje() string {
+	return fmt.Sprintf("El teléfono debe tener exactamente %d dígitos sin letras", v.config.TelefonoDigitos)
+}
+
+// CorreoMensaje mensaje de error del correo según la configuración
+func (v *ContactoValidator) CorreoMensaje() string {
+	proveedores := make([]string, len(v.config.DominiosCorreo))
+	for i, dominio := range v.config.DominiosCorreo {
+		proveedores[i] = strings.SplitN(dominio, ".", 2)[0]
+	}
+	return fmt.Sprintf("El correo debe ser de un proveedor conocido (%s)", strings.Join(proveedores, ", "))
+}
+
+// NombreMensaje mensaje de error del nombre
+func (v *ContactoValidator) NombreMensaje() string {
+	return "El nombre no debe contener números ni estar vacío"
+}
+
+// ClaveMensaje mensaje de error de la clave cliente
+func (v *ContactoValidator) ClaveMensaje() string {
+	if v.config.ClaveMinima == 1 {
+		return "La clave cliente debe ser un número mayor a 0"
 	}
+	return fmt.Sprintf("La clave cliente debe ser un número mayor o igual a %d", v.config.ClaveMinima)
 }
 
 // ValidarContacto valida un contacto completo
@@ -55,7 +156,7 @@ func (v *ContactoValidator) ValidarTelefono(telefono string) *models.ErrorRespon
 	if !v.telefonoRegex.MatchString(telefono) {
 		return &models.ErrorResponse{
 			Campo:   "telefonoContacto",
-			Mensaje: "El teléfono debe tener exactamente 10 dígitos sin letras",
+			Mensaje: v.TelefonoMensaje(),
 		}
 	}
 	return nil
@@ -66,7 +167,7 @@ func (v *ContactoValidator) ValidarCorreo(correo string) *models.ErrorResponse {
 	if !v.correoRegex.MatchString(correo) {
 		return &models.ErrorResponse{
 			Campo:   "correo",
-			Mensaje: "El correo debe ser de un proveedor conocido (gmail, yahoo, hotmail, outlook, live, icloud, protonmail)",
+			Mensaje: v.CorreoMensaje(),
 		}
 	}
 	return nil
@@ -77,7 +178,7 @@ func (v *ContactoValidator) ValidarNombre(nombre string) *models.ErrorResponse {
 	if !v.nombreRegex.MatchString(nombre) || strings.TrimSpace(nombre) == "" {
 		return &models.ErrorResponse{
 			Campo:   "nombre",
-			Mensaje: "El nombre no debe contener números ni estar vacío",
+			Mensaje: v.NombreMensaje(),
 		}
 	}
 	return nil
@@ -85,15 +186,38 @@ func (v *ContactoValidator) ValidarNombre(nombre string) *models.ErrorResponse {
 
 // ValidarClaveCliente valida la clave del cliente
 func (v *ContactoValidator) ValidarClaveCliente(clave int) *models.ErrorResponse {
-	if clave <= 0 {
+	if clave < v.config.ClaveMinima {
 		return &models.ErrorResponse{
 			Campo:   "claveCliente",
-			Mensaje: "La clave cliente debe ser un número mayor a 0",
+			Mensaje: v.ClaveMensaje(),
 		}
 	}
 	return nil
 }
 
+// CamposValidados campos con regla de validación, con su nombre JSON
+var CamposValidados = []string{"claveCliente", "nombre", "correo", "telefonoContacto"}
+
+// ValidarCampo aplica la regla activa de un campo a su valor en texto (como viene del archivo)
+func (v *ContactoValidator) ValidarCampo(campo, valor string) *models.ErrorResponse {
+	switch campo {
+	case "claveCliente":
+		clave, err := strconv.Atoi(strings.TrimSpace(valor))
+		if err != nil {
+			return &models.ErrorResponse{Campo: campo, Mensaje: v.ClaveMensaje()}
+		}
+		return v.ValidarClaveCliente(clave)
+	case "nombre":
+		return v.ValidarNombre(valor)
+	case "correo":
+		return v.ValidarCorreo(valor)
+	case "telefonoContacto":
+		return v.ValidarTelefono(valor)
+	default:
+		return &models.ErrorResponse{Campo: campo, Mensaje: fmt.Sprintf("Campo '%s' sin regla de validación", campo)}
+	}
+}
+
 // ValidarBusqueda valida los parámetros de búsqueda
 func (v *ContactoValidator) ValidarBusqueda(dto *models.ContactoDTO) []models.ErrorResponse {
 	var errores []models.ErrorResponse